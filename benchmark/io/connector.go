@@ -21,6 +21,7 @@
 package io
 
 import (
+	"encoding/json"
 	"log"
 	"net"
 	"sync"
@@ -55,6 +56,12 @@ type (
 	// when the correspond events is occurred.
 	Callback func(data interface{})
 
+	// ResponseError is passed to the response callback instead of the data
+	// when the server responded an error
+	ResponseError struct {
+		Data []byte // JSON encoded error, e.g: {"code": 500, "message": "failed"}
+	}
+
 	// Connector is a tiny Nano client
 	Connector struct {
 		conn   net.Conn       // low-level connection
@@ -71,10 +78,22 @@ type (
 		muResponses sync.RWMutex
 		responses   map[uint64]Callback
 
-		connectedCallback func() // connected callback
+		connectedCallback func()            // connected callback
+		kickedCallback    func(data []byte) // kicked callback
+		handshakeCallback func(data []byte) // handshake callback
+
+		muSys       sync.RWMutex
+		resumeToken string            // token to resume the session after reconnect
+		dict        map[string]uint16 // route dictionary cached from server
+		dictHash    string            // hash of the cached route dictionary
 	}
 )
 
+// Error implements the error interface
+func (e *ResponseError) Error() string {
+	return string(e.Data)
+}
+
 // NewConnector create a new Connector
 func NewConnector() *Connector {
 	return &Connector{
@@ -99,7 +118,11 @@ func (c *Connector) Start(addr string) error {
 	go c.write()
 
 	// send handshake packet
-	c.send(hsd)
+	handshake, err := c.handshake()
+	if err != nil {
+		return err
+	}
+	c.send(handshake)
 
 	// read and process network message
 	go c.read()
@@ -107,11 +130,82 @@ func (c *Connector) Start(addr string) error {
 	return nil
 }
 
+// ResumeToken returns the resume token issued by server, it is empty if the server
+// does not enable session resuming
+func (c *Connector) ResumeToken() string {
+	c.muSys.RLock()
+	defer c.muSys.RUnlock()
+
+	return c.resumeToken
+}
+
+// SetResumeToken sets the token which will be presented in handshake to resume the
+// previous session
+func (c *Connector) SetResumeToken(token string) {
+	c.muSys.Lock()
+	defer c.muSys.Unlock()
+
+	c.resumeToken = token
+}
+
+// Dictionary returns the route dictionary sent by server in handshake, the
+// dictionary is cached and will not be sent again after reconnect
+func (c *Connector) Dictionary() map[string]uint16 {
+	c.muSys.RLock()
+	defer c.muSys.RUnlock()
+
+	return c.dict
+}
+
+// SetDictionary sets the cached route dictionary and its hash, the server will
+// not send the dictionary in handshake if the hash is not changed
+func (c *Connector) SetDictionary(dict map[string]uint16, hash string) {
+	c.muSys.Lock()
+	defer c.muSys.Unlock()
+
+	c.dict = dict
+	c.dictHash = hash
+}
+
+func (c *Connector) handshake() ([]byte, error) {
+	c.muSys.RLock()
+	defer c.muSys.RUnlock()
+
+	if c.resumeToken == "" && c.dictHash == "" {
+		return hsd, nil
+	}
+
+	sys := map[string]string{}
+	if c.resumeToken != "" {
+		sys["resume"] = c.resumeToken
+	}
+	if c.dictHash != "" {
+		sys["dictHash"] = c.dictHash
+	}
+	data, err := json.Marshal(map[string]interface{}{"sys": sys})
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(packet.Handshake, data)
+}
+
 // OnConnected set the callback which will be called when the client connected to the server
 func (c *Connector) OnConnected(callback func()) {
 	c.connectedCallback = callback
 }
 
+// OnHandshake set the callback which will be called when the client received the
+// handshake response, data is the response
+func (c *Connector) OnHandshake(callback func(data []byte)) {
+	c.handshakeCallback = callback
+}
+
+// OnKicked set the callback which will be called when the client kicked by the server,
+// data is the kick reason
+func (c *Connector) OnKicked(callback func(data []byte)) {
+	c.kickedCallback = callback
+}
+
 // Request send a request to server and register a callbck for the response
 func (c *Connector) Request(route string, v proto.Message, callback Callback) error {
 	data, err := serialize(v)
@@ -161,7 +255,11 @@ func (c *Connector) On(event string, callback Callback) {
 // Close close the connection, and shutdown the benchmark
 func (c *Connector) Close() {
 	c.conn.Close()
-	close(c.die)
+	select {
+	case <-c.die:
+	default:
+		close(c.die)
+	}
 }
 
 func (c *Connector) eventHandler(event string) (Callback, bool) {
@@ -259,8 +357,31 @@ func (c *Connector) read() {
 func (c *Connector) processPacket(p *packet.Packet) {
 	switch p.Type {
 	case packet.Handshake:
+		res := struct {
+			Sys struct {
+				Resume   string            `json:"resume"`
+				Dict     map[string]uint16 `json:"dict"`
+				DictHash string            `json:"dictHash"`
+			} `json:"sys"`
+		}{}
+		if err := json.Unmarshal(p.Data, &res); err == nil {
+			c.muSys.Lock()
+			if res.Sys.Resume != "" {
+				c.resumeToken = res.Sys.Resume
+			}
+			if res.Sys.Dict != nil {
+				c.dict = res.Sys.Dict
+				c.dictHash = res.Sys.DictHash
+			}
+			c.muSys.Unlock()
+		}
+		if c.handshakeCallback != nil {
+			c.handshakeCallback(p.Data)
+		}
 		c.send(had)
-		c.connectedCallback()
+		if c.connectedCallback != nil {
+			c.connectedCallback()
+		}
 	case packet.Data:
 		msg, err := message.Decode(p.Data)
 		if err != nil {
@@ -270,6 +391,9 @@ func (c *Connector) processPacket(p *packet.Packet) {
 		c.processMessage(msg)
 
 	case packet.Kick:
+		if c.kickedCallback != nil {
+			c.kickedCallback(p.Data)
+		}
 		c.Close()
 	}
 }
@@ -292,7 +416,11 @@ func (c *Connector) processMessage(msg *message.Message) {
 			return
 		}
 
-		cb(msg.Data)
+		if msg.Error {
+			cb(&ResponseError{Data: msg.Data})
+		} else {
+			cb(msg.Data)
+		}
 		c.setResponseHandler(msg.ID, nil)
 	}
 }
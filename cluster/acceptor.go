@@ -3,6 +3,8 @@ package cluster
 import (
 	"context"
 	"net"
+	"sync"
+	"sync/atomic"
 
 	"github.com/revzim/nano/cluster/clusterpb"
 	"github.com/revzim/nano/internal/message"
@@ -15,8 +17,14 @@ type acceptor struct {
 	gateClient clusterpb.MemberClient
 	session    *session.Session
 	lastMid    uint64
+	answered   uint64 // last message id which was responded
 	rpcHandler rpcHandler
 	gateAddr   string
+	node       *Node
+
+	bindMu      sync.Mutex
+	bindVersion uint64 // the latest bind version of gate session seen
+	bindPending bool   // whether the uid changed by members has not been seen from gate
 }
 
 // Push implements the session.NetworkEntity interface
@@ -31,12 +39,22 @@ func (a *acceptor) Push(route string, v interface{}) error {
 		Route:     route,
 		Data:      data,
 	}
+	if a.node.StreamTransport {
+		return a.node.sendStream(a.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Push{Push: request},
+		})
+	}
 	_, err = a.gateClient.HandlePush(context.Background(), request)
 	return err
 }
 
 // RPC implements the session.NetworkEntity interface
 func (a *acceptor) RPC(route string, v interface{}) error {
+	return a.RPCContext(context.Background(), route, v)
+}
+
+// RPCContext implements the session.ContextRPC interface
+func (a *acceptor) RPCContext(ctx context.Context, route string, v interface{}) error {
 	// TODO: buffer
 	data, err := message.Serialize(v)
 	if err != nil {
@@ -47,7 +65,7 @@ func (a *acceptor) RPC(route string, v interface{}) error {
 		Route: route,
 		Data:  data,
 	}
-	a.rpcHandler(a.session, msg, true)
+	a.rpcHandler(a.session, msg, metaFromContext(ctx), true)
 	return nil
 }
 
@@ -68,12 +86,34 @@ func (a *acceptor) ResponseMid(mid uint64, v interface{}) error {
 	if err != nil {
 		return err
 	}
-	request := &clusterpb.ResponseMessage{
+	if err := a.response(&clusterpb.ResponseMessage{
 		SessionId: a.sid,
 		Id:        mid,
 		Data:      data,
+	}); err != nil {
+		return err
 	}
-	_, err = a.gateClient.HandleResponse(context.Background(), request)
+	atomic.StoreUint64(&a.answered, mid)
+	return nil
+}
+
+// responseError responds the encoded error to the request via the gate
+func (a *acceptor) responseError(mid uint64, data []byte) error {
+	return a.response(&clusterpb.ResponseMessage{
+		SessionId: a.sid,
+		Id:        mid,
+		Data:      data,
+		Error:     true,
+	})
+}
+
+func (a *acceptor) response(request *clusterpb.ResponseMessage) error {
+	if a.node.StreamTransport {
+		return a.node.sendStream(a.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Response{Response: request},
+		})
+	}
+	_, err := a.gateClient.HandleResponse(context.Background(), request)
 	return err
 }
 
@@ -83,10 +123,35 @@ func (a *acceptor) Close() error {
 	request := &clusterpb.CloseSessionRequest{
 		SessionId: a.sid,
 	}
+	if a.node.StreamTransport {
+		return a.node.sendStream(a.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_CloseSession{CloseSession: request},
+		})
+	}
 	_, err := a.gateClient.CloseSession(context.Background(), request)
 	return err
 }
 
+// Kick implements the session.Kicker interface, the gate kicks the client with
+// the reason
+func (a *acceptor) Kick(reason interface{}) error {
+	data, err := kickPayload(reason)
+	if err != nil {
+		return err
+	}
+	request := &clusterpb.KickSessionRequest{
+		SessionId: a.sid,
+		Data:      data,
+	}
+	if a.node.StreamTransport {
+		return a.node.sendStream(a.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_KickSession{KickSession: request},
+		})
+	}
+	_, err = a.gateClient.KickSession(context.Background(), request)
+	return err
+}
+
 // RemoteAddr implements the session.NetworkEntity interface
 func (*acceptor) RemoteAddr() net.Addr {
 	return mock.NetAddr{}
@@ -0,0 +1,131 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/revzim/nano/internal/codec"
+	"github.com/revzim/nano/internal/log"
+	"github.com/revzim/nano/internal/packet"
+	"github.com/revzim/nano/session"
+)
+
+// AdmissionKick is the payload of the kick packet sent to the connection which
+// is rejected by the admission control
+type AdmissionKick struct {
+	Reason string `json:"reason"`
+}
+
+// admission counts the connections of gate to enforce the MaxSessions and the
+// MaxSessionsPerIP
+type admission struct {
+	sync.Mutex
+	sessions int
+	ips      map[string]int
+}
+
+func newAdmission() *admission {
+	return &admission{ips: map[string]int{}}
+}
+
+// remoteIP returns the ip of remote address, the address will be returned as is
+// if it does not contain a port
+func remoteIP(addr net.Addr) string {
+	ip := addr.String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return ip
+}
+
+// admit decides whether the connection can be accepted, it returns the reason if
+// the connection is rejected, the accepted connection should leave after closed
+func (n *Node) admit(conn net.Conn) (string, string) {
+	ip := remoteIP(conn.RemoteAddr())
+
+	a := n.admission
+	a.Lock()
+	defer a.Unlock()
+
+	if n.MaxSessions > 0 && a.sessions >= n.MaxSessions {
+		return ip, "too many sessions"
+	}
+	if n.MaxSessionsPerIP > 0 && a.ips[ip] >= n.MaxSessionsPerIP {
+		return ip, "too many sessions from the same ip"
+	}
+	a.sessions++
+	a.ips[ip]++
+	return ip, ""
+}
+
+func (n *Node) leave(ip string) {
+	a := n.admission
+	a.Lock()
+	defer a.Unlock()
+
+	a.sessions--
+	a.ips[ip]--
+	if a.ips[ip] < 1 {
+		delete(a.ips, ip)
+	}
+}
+
+// reject sends a kick packet with the reason to the connection and closes it
+func reject(conn net.Conn, reason string) {
+	log.Println(fmt.Sprintf("Connection rejected, Remote=%s, Reason=%s", conn.RemoteAddr(), reason))
+	defer conn.Close()
+
+	payload, err := kickPayload(&AdmissionKick{Reason: reason})
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	p, err := codec.Encode(packet.Kick, payload)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	conn.SetWriteDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Write(p); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// checkHandshake closes the agent which has not finished handshake before the
+// HandshakeTimeout elapsed, it returns the timer which should be stopped after
+// the agent closed
+func (n *Node) checkHandshake(agent *agent) *time.Timer {
+	if n.HandshakeTimeout <= 0 {
+		return nil
+	}
+	return time.AfterFunc(n.HandshakeTimeout, func() {
+		if agent.status() >= statusWorking {
+			return
+		}
+		log.Println(fmt.Sprintf("Session handshake timeout, Remote=%s", agent.conn.RemoteAddr()))
+		agent.setCloseReason(session.CloseReasonHandshakeTimeout)
+		agent.Close()
+	})
+}
@@ -21,10 +21,13 @@
 package cluster
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -34,7 +37,6 @@ import (
 	"github.com/revzim/nano/internal/message"
 	"github.com/revzim/nano/internal/packet"
 	"github.com/revzim/nano/pipeline"
-	"github.com/revzim/nano/scheduler"
 	"github.com/revzim/nano/session"
 )
 
@@ -54,9 +56,13 @@ type (
 	// Agent corresponding a user, used for store raw conn information
 	agent struct {
 		// regular agent member
+		mu       sync.RWMutex        // protects session and srv
 		session  *session.Session    // session
 		conn     net.Conn            // low-level conn fd
+		node     *Node               // current node
 		lastMid  uint64              // last message id
+		answered uint64              // last message id which was responded
+		rebinds  uint64              // count of uid changes of session, see bindVersion
 		state    int32               // current agent state
 		chDie    chan struct{}       // wait for close
 		chSend   chan pendingMessage // push message queue
@@ -66,6 +72,11 @@ type (
 
 		rpcHandler rpcHandler
 		srv        reflect.Value // cached session reflect.Value
+
+		resumeToken string // token to resume the session after reconnect
+		noResume    int32  // whether the agent was closed by server, the session will not be resumed
+		closeReason int32  // why the agent was closed, see session.CloseReason
+		limiter     *sessionLimiter
 	}
 
 	pendingMessage struct {
@@ -73,13 +84,16 @@ type (
 		route   string       // message route(push)
 		mid     uint64       // response message id(response)
 		payload interface{}  // payload
+		err     bool         // whether the response is an error
+		kick    bool         // kick the client after pending messages sent
 	}
 )
 
 // Create new agent instance
-func newAgent(conn net.Conn, pipeline pipeline.Pipeline, rpcHandler rpcHandler) *agent {
+func newAgent(conn net.Conn, node *Node, pipeline pipeline.Pipeline, rpcHandler rpcHandler) *agent {
 	a := &agent{
 		conn:       conn,
+		node:       node,
 		state:      statusStart,
 		chDie:      make(chan struct{}),
 		lastAt:     time.Now().Unix(),
@@ -91,20 +105,42 @@ func newAgent(conn net.Conn, pipeline pipeline.Pipeline, rpcHandler rpcHandler)
 
 	// binding session
 	s := session.New(a)
+	if node.SessionStore != nil {
+		s.SetStore(node.SessionStore)
+	}
 	a.session = s
 	a.srv = reflect.ValueOf(s)
 
 	return a
 }
 
-func (a *agent) send(m pendingMessage) (err error) {
-	defer func() {
-		if e := recover(); e != nil {
-			err = ErrBrokenPipe
-		}
-	}()
-	a.chSend <- m
-	return
+// currentSession returns the session of agent, which is replaced when the client
+// resumed a parked session
+func (a *agent) currentSession() *session.Session {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.session
+}
+
+// attach attaches the session to the agent and returns the previous one
+func (a *agent) attach(s *session.Session) *session.Session {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	prev := a.session
+	a.session = s
+	a.srv = reflect.ValueOf(s)
+	return prev
+}
+
+func (a *agent) send(m pendingMessage) error {
+	select {
+	case a.chSend <- m:
+		return nil
+	case <-a.chDie:
+		return ErrBrokenPipe
+	}
 }
 
 // LastMid implements the session.NetworkEntity interface
@@ -126,10 +162,10 @@ func (a *agent) Push(route string, v interface{}) error {
 		switch d := v.(type) {
 		case []byte:
 			log.Println(fmt.Sprintf("Type=Push, ID=%d, UID=%d, Route=%s, Data=%dbytes",
-				a.session.ID(), a.session.UID(), route, len(d)))
+				a.currentSession().ID(), a.currentSession().UID(), route, len(d)))
 		default:
 			log.Println(fmt.Sprintf("Type=Push, ID=%d, UID=%d, Route=%s, Data=%+v",
-				a.session.ID(), a.session.UID(), route, v))
+				a.currentSession().ID(), a.currentSession().UID(), route, v))
 		}
 	}
 
@@ -138,6 +174,11 @@ func (a *agent) Push(route string, v interface{}) error {
 
 // RPC, implementation for session.NetworkEntity interface
 func (a *agent) RPC(route string, v interface{}) error {
+	return a.RPCContext(context.Background(), route, v)
+}
+
+// RPCContext implements the session.ContextRPC interface
+func (a *agent) RPCContext(ctx context.Context, route string, v interface{}) error {
 	if a.status() == statusClosed {
 		return ErrBrokenPipe
 	}
@@ -152,7 +193,7 @@ func (a *agent) RPC(route string, v interface{}) error {
 		Route: route,
 		Data:  data,
 	}
-	a.rpcHandler(a.session, msg, true)
+	a.rpcHandler(a.currentSession(), msg, metaFromContext(ctx), true)
 	return nil
 }
 
@@ -165,6 +206,15 @@ func (a *agent) Response(v interface{}) error {
 // ResponseMid, implementation for session.NetworkEntity interface
 // Response message to session
 func (a *agent) ResponseMid(mid uint64, v interface{}) error {
+	return a.responseMid(mid, v, false)
+}
+
+// responseError responds the encoded error to the request
+func (a *agent) responseError(mid uint64, data []byte) error {
+	return a.responseMid(mid, data, true)
+}
+
+func (a *agent) responseMid(mid uint64, v interface{}, isErr bool) error {
 	if a.status() == statusClosed {
 		return ErrBrokenPipe
 	}
@@ -181,20 +231,77 @@ func (a *agent) ResponseMid(mid uint64, v interface{}) error {
 		switch d := v.(type) {
 		case []byte:
 			log.Println(fmt.Sprintf("Type=Response, ID=%d, UID=%d, MID=%d, Data=%dbytes",
-				a.session.ID(), a.session.UID(), mid, len(d)))
+				a.currentSession().ID(), a.currentSession().UID(), mid, len(d)))
 		default:
 			log.Println(fmt.Sprintf("Type=Response, ID=%d, UID=%d, MID=%d, Data=%+v",
-				a.session.ID(), a.session.UID(), mid, v))
+				a.currentSession().ID(), a.currentSession().UID(), mid, v))
 		}
 	}
 
-	return a.send(pendingMessage{typ: message.Response, mid: mid, payload: v})
+	if err := a.send(pendingMessage{typ: message.Response, mid: mid, payload: v, err: isErr}); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&a.answered, mid)
+	return nil
+}
+
+// Kick, implementation for session.Kicker interface
+// Kick sends a kick packet with the reason to client after the pending messages,
+// and then closes the agent. The reason will be encoded by the serializer unless it
+// is []byte, see kickPayload
+func (a *agent) Kick(reason interface{}) error {
+	if a.status() == statusClosed {
+		// the session is waiting for resuming
+		if a.node.discardParked(a.currentSession(), session.CloseReasonKick) {
+			return nil
+		}
+		return ErrBrokenPipe
+	}
+
+	payload, err := kickPayload(reason)
+	if err != nil {
+		return err
+	}
+	p, err := codec.Encode(packet.Kick, payload)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&a.noResume, 1)
+	a.setCloseReason(session.CloseReasonKick)
+	return a.send(pendingMessage{kick: true, payload: p})
+}
+
+// kickPayload encodes the kick reason by the serializer like pushed messages, the
+// reason which cannot be encoded by the serializer is encoded as JSON, e.g: the
+// builtin kick reasons(DrainKick, AdmissionKick and RateLimitedKick) with the
+// protobuf serializer
+func kickPayload(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := message.Serialize(v)
+	if err != nil {
+		return json.Marshal(v)
+	}
+	return data, nil
 }
 
 // Close, implementation for session.NetworkEntity interface
 // Close closes the agent, clean inner state and close low-level connection.
 // Any blocked Read or Write operations will be unblocked and return errors.
+// The session closed by server will not be resumed.
 func (a *agent) Close() error {
+	atomic.StoreInt32(&a.noResume, 1)
+	if a.status() == statusClosed && a.node.discardParked(a.currentSession(), session.CloseReasonServer) {
+		return nil
+	}
+	a.setCloseReason(session.CloseReasonServer)
+	return a.close()
+}
+
+// close closes the agent without preventing the session being resumed, e.g: the
+// low-level connection was broken
+func (a *agent) close() error {
 	if a.status() == statusClosed {
 		return ErrCloseClosedSession
 	}
@@ -202,7 +309,7 @@ func (a *agent) Close() error {
 
 	if env.Debug {
 		log.Println(fmt.Sprintf("Session closed, ID=%d, UID=%d, IP=%s",
-			a.session.ID(), a.session.UID(), a.conn.RemoteAddr()))
+			a.currentSession().ID(), a.currentSession().UID(), a.conn.RemoteAddr()))
 	}
 
 	// prevent closing closed channel
@@ -211,7 +318,6 @@ func (a *agent) Close() error {
 		// expect
 	default:
 		close(a.chDie)
-		scheduler.PushTask(func() { session.Lifetime.Close(a.session) })
 	}
 
 	return a.conn.Close()
@@ -228,6 +334,16 @@ func (a *agent) String() string {
 	return fmt.Sprintf("Remote=%s, LastTime=%d", a.conn.RemoteAddr().String(), atomic.LoadInt64(&a.lastAt))
 }
 
+// setCloseReason records why the agent was closed, the reason recorded first
+// will be kept
+func (a *agent) setCloseReason(reason session.CloseReason) {
+	atomic.CompareAndSwapInt32(&a.closeReason, int32(session.CloseReasonUnknown), int32(reason))
+}
+
+func (a *agent) reason() session.CloseReason {
+	return session.CloseReason(atomic.LoadInt32(&a.closeReason))
+}
+
 func (a *agent) status() int32 {
 	return atomic.LoadInt32(&a.state)
 }
@@ -242,11 +358,10 @@ func (a *agent) write() {
 	// clean func
 	defer func() {
 		ticker.Stop()
-		close(a.chSend)
 		close(chWrite)
-		a.Close()
+		a.close()
 		if env.Debug {
-			log.Println(fmt.Sprintf("Session write goroutine exit, SessionID=%d, UID=%d", a.session.ID(), a.session.UID()))
+			log.Println(fmt.Sprintf("Session write goroutine exit, SessionID=%d, UID=%d", a.currentSession().ID(), a.currentSession().UID()))
 		}
 	}()
 
@@ -256,6 +371,7 @@ func (a *agent) write() {
 			deadline := time.Now().Add(-2 * env.Heartbeat).Unix()
 			if atomic.LoadInt64(&a.lastAt) < deadline {
 				log.Println(fmt.Sprintf("Session heartbeat timeout, LastTime=%d, Deadline=%d", atomic.LoadInt64(&a.lastAt), deadline))
+				a.setCloseReason(session.CloseReasonHeartbeatTimeout)
 				return
 			}
 			chWrite <- hbd
@@ -264,10 +380,26 @@ func (a *agent) write() {
 			// close agent while low-level conn broken
 			if _, err := a.conn.Write(data); err != nil {
 				log.Println(err.Error())
+				a.setCloseReason(session.CloseReasonWriteError)
 				return
 			}
 
 		case data := <-a.chSend:
+			if data.kick {
+				// flush the pending messages before kick packet
+				for len(chWrite) > 0 {
+					if _, err := a.conn.Write(<-chWrite); err != nil {
+						log.Println(err.Error())
+						a.setCloseReason(session.CloseReasonWriteError)
+						return
+					}
+				}
+				if _, err := a.conn.Write(data.payload.([]byte)); err != nil {
+					log.Println(err.Error())
+				}
+				return
+			}
+
 			payload, err := message.Serialize(data.payload)
 			if err != nil {
 				switch data.typ {
@@ -287,9 +419,10 @@ func (a *agent) write() {
 				Data:  payload,
 				Route: data.route,
 				ID:    data.mid,
+				Error: data.err,
 			}
 			if pipe := a.pipeline; pipe != nil {
-				err := pipe.Outbound().Process(a.session, m)
+				err := pipe.Outbound().Process(a.currentSession(), m)
 				if err != nil {
 					log.Println("broken pipeline", err.Error())
 					break
@@ -314,6 +447,7 @@ func (a *agent) write() {
 			return
 
 		case <-env.Die: // application quit
+			a.setCloseReason(session.CloseReasonShutdown)
 			return
 		}
 	}
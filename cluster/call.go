@@ -0,0 +1,345 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/errcode"
+	"github.com/revzim/nano/internal/env"
+	"github.com/revzim/nano/internal/message"
+	"github.com/revzim/nano/mock"
+	"github.com/revzim/nano/scheduler"
+	"github.com/revzim/nano/session"
+)
+
+// callEntity is the network entity of the session which is used to handle
+// a cluster call, it captures the response or error of the handler
+type callEntity struct {
+	once   sync.Once
+	result chan *clusterpb.CallResponse
+}
+
+func newCallEntity() *callEntity {
+	return &callEntity{result: make(chan *clusterpb.CallResponse, 1)}
+}
+
+func (c *callEntity) done(resp *clusterpb.CallResponse) {
+	c.once.Do(func() { c.result <- resp })
+}
+
+// fail replies the caller with the error of handler
+func (c *callEntity) fail(e *errcode.Error) {
+	c.done(&clusterpb.CallResponse{Error: e.Message, Code: int32(e.Code)})
+}
+
+// Push implements the session.NetworkEntity interface
+func (c *callEntity) Push(_ string, _ interface{}) error {
+	return ErrPushOnCall
+}
+
+// RPC implements the session.NetworkEntity interface
+func (c *callEntity) RPC(_ string, _ interface{}) error {
+	return ErrPushOnCall
+}
+
+// LastMid implements the session.NetworkEntity interface
+func (c *callEntity) LastMid() uint64 {
+	return 0
+}
+
+// Response implements the session.NetworkEntity interface
+func (c *callEntity) Response(v interface{}) error {
+	data, err := message.Serialize(v)
+	if err != nil {
+		return err
+	}
+	c.done(&clusterpb.CallResponse{Data: data})
+	return nil
+}
+
+// ResponseMid implements the session.NetworkEntity interface
+func (c *callEntity) ResponseMid(_ uint64, v interface{}) error {
+	return c.Response(v)
+}
+
+// Close implements the session.NetworkEntity interface
+func (c *callEntity) Close() error {
+	return nil
+}
+
+// RemoteAddr implements the session.NetworkEntity interface
+func (*callEntity) RemoteAddr() net.Addr {
+	return mock.NetAddr{}
+}
+
+// RemoteError represents an error returned by the handler of a cluster call,
+// Code is the code of errcode.Error
+type RemoteError struct {
+	Route   string
+	Code    int
+	Message string
+}
+
+// Error implements the error interface
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("nano/cluster: call %s failed: %s", e.Route, e.Message)
+}
+
+// Call calls the handler of route in cluster and waits for its response, the
+// response will be deserialized to resp with the serializer of current node
+// (resp can be *[]byte to receive the raw data). The call will be cancelled
+// when ctx is done, and a *RemoteError will be returned if the handler failed.
+// The call finishes with an empty response if the handler replied nothing.
+//
+// A handler running on the scheduler should pass its context.Context to call other
+// handlers, and ErrCallOnScheduler is returned without it. The handlers called back
+// by the call, e.g: the handler of current node or A->B->A callbacks, run on the
+// waiting scheduler until the call finished. The calls made in other goroutines do
+// not block the scheduler, the handlers called back by them are scheduled as usual.
+// The access control of handler is not checked for the call, see component.Access.
+func (n *Node) Call(ctx context.Context, route string, req, resp interface{}) error {
+	index := strings.LastIndex(route, ".")
+	if index < 0 {
+		return fmt.Errorf("nano/cluster: invalid route %s", route)
+	}
+
+	data, err := message.Serialize(req)
+	if err != nil {
+		return err
+	}
+	request := &clusterpb.CallRequest{
+		Route:    route,
+		Data:     data,
+		Metadata: session.MetadataFromContext(ctx),
+		Chain:    callChain(ctx),
+	}
+
+	var response *clusterpb.CallResponse
+	invoke := func() {
+		if _, found := n.handler.localHandlers[route]; found {
+			response, err = n.HandleCall(ctx, request)
+		} else {
+			response, err = n.remoteCall(ctx, route[:index], request)
+		}
+	}
+	if onScheduler() {
+		// The chain of the calls waited by the schedulers is lost without the
+		// context of handler, the handlers called back will wait forever
+		if !scheduledContext(ctx) {
+			return ErrCallOnScheduler
+		}
+		waitCall(request, invoke)
+	} else {
+		invoke()
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	if response.Error != "" {
+		return &RemoteError{Route: route, Code: int(response.Code), Message: response.Error}
+	}
+
+	if resp == nil {
+		return nil
+	}
+	if raw, ok := resp.(*[]byte); ok {
+		*raw = response.Data
+		return nil
+	}
+	return env.Serializer.Unmarshal(response.Data, resp)
+}
+
+// waitCall makes the call on the scheduler, the handlers called back by the call
+// are run on the scheduler until the call finished
+func waitCall(request *clusterpb.CallRequest, invoke func()) {
+	id := newCallID()
+	call := waitingCalls.register(id)
+	chain := make([]string, len(request.Chain), len(request.Chain)+1)
+	copy(chain, request.Chain)
+	request.Chain = append(chain, id)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		invoke()
+	}()
+	for {
+		select {
+		case <-call.chReady:
+			for _, task := range waitingCalls.take(call) {
+				task()
+			}
+		case <-done:
+			// The handlers called back before the call unregistered
+			for _, task := range waitingCalls.unregister(id) {
+				task()
+			}
+			return
+		}
+	}
+}
+
+// waitingCalls are the calls which the global scheduler is waiting for
+var waitingCalls = &callWaiters{calls: map[string]*waitingCall{}}
+
+type callWaiters struct {
+	sync.Mutex
+	calls map[string]*waitingCall
+}
+
+// waitingCall is a call made on the scheduler, the handlers called back by the
+// call are queued to it and run by the waiting scheduler
+type waitingCall struct {
+	tasks   []scheduler.Task
+	chReady chan struct{} // notified when a task queued
+}
+
+func (w *callWaiters) register(id string) *waitingCall {
+	w.Lock()
+	defer w.Unlock()
+
+	call := &waitingCall{chReady: make(chan struct{}, 1)}
+	w.calls[id] = call
+	return call
+}
+
+// unregister removes the call finished and returns the tasks not run
+func (w *callWaiters) unregister(id string) []scheduler.Task {
+	w.Lock()
+	defer w.Unlock()
+
+	call, found := w.calls[id]
+	if !found {
+		return nil
+	}
+	delete(w.calls, id)
+	return call.tasks
+}
+
+// take returns the tasks queued to the call and clears them
+func (w *callWaiters) take(call *waitingCall) []scheduler.Task {
+	w.Lock()
+	defer w.Unlock()
+
+	tasks := call.tasks
+	call.tasks = nil
+	return tasks
+}
+
+// schedule queues the task to the latest call of the chain which is still waited
+// by the scheduler, it returns false if no call of the chain is waiting, e.g: the
+// caller has timed out
+func (w *callWaiters) schedule(chain []string, task scheduler.Task) bool {
+	w.Lock()
+	defer w.Unlock()
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if call, found := w.calls[chain[i]]; found {
+			call.tasks = append(call.tasks, task)
+			select {
+			case call.chReady <- struct{}{}:
+			default:
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// schedulerGoroutine is the id of the goroutine running the global scheduler
+var schedulerGoroutine int64
+
+// markScheduler records the goroutine of the global scheduler, which should be
+// run by the scheduler
+func markScheduler() {
+	atomic.StoreInt64(&schedulerGoroutine, goroutineID())
+}
+
+// onScheduler returns whether current goroutine is the global scheduler
+func onScheduler() bool {
+	id := atomic.LoadInt64(&schedulerGoroutine)
+	return id != 0 && id == goroutineID()
+}
+
+// goroutineID returns the id of current goroutine, which is parsed from the first
+// line of the stack, e.g: "goroutine 18 [running]:"
+func goroutineID() int64 {
+	var buf [64]byte
+	fields := bytes.Fields(buf[:runtime.Stack(buf[:], false)])
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseInt(string(fields[1]), 10, 64)
+	return id
+}
+
+func (n *Node) remoteCall(ctx context.Context, service string, request *clusterpb.CallRequest) (*clusterpb.CallResponse, error) {
+	members := n.handler.findMembers(service)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("nano/cluster: %s not found(forgot registered?)", request.Route)
+	}
+	remoteAddr := n.handler.selectMember(nil, service, members).ServiceAddr
+	pool, err := n.rpcClient.getConnPool(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	return clusterpb.NewMemberClient(pool.Get()).HandleCall(ctx, request)
+}
+
+// HandleCall implements the MemberServer interface
+func (n *Node) HandleCall(ctx context.Context, req *clusterpb.CallRequest) (*clusterpb.CallResponse, error) {
+	handler, found := n.handler.localHandlers[req.Route]
+	if !found {
+		return nil, fmt.Errorf("service not found in current node: %v", req.Route)
+	}
+
+	entity := newCallEntity()
+	s := session.New(entity)
+	msg := &message.Message{
+		Type:  message.Request,
+		Route: req.Route,
+		Data:  req.Data,
+	}
+	meta := metaFromContext(ctx)
+	meta.metadata = req.Metadata
+	meta.chain = req.Chain
+	n.handler.localProcess(handler, 0, s, msg, meta)
+
+	select {
+	case resp := <-entity.result:
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
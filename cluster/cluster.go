@@ -24,9 +24,13 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/revzim/nano/cluster/clusterpb"
 	"github.com/revzim/nano/internal/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // cluster represents a nano cluster, which contains a bunch of nano nodes
@@ -38,100 +42,238 @@ type cluster struct {
 	currentNode *Node
 	rpcClient   *rpcClient
 	members     []*Member
+	active      int32 // whether the current node serves as master
 }
 
 func newCluster(currentNode *Node) *cluster {
 	return &cluster{currentNode: currentNode}
 }
 
-// Register implements the MasterServer gRPC service
+// Register implements the MasterServer gRPC service, a member which has been
+// registered can register again to update its member information
 func (c *cluster) Register(_ context.Context, req *clusterpb.RegisterRequest) (*clusterpb.RegisterResponse, error) {
 	if req.MemberInfo == nil {
 		return nil, ErrInvalidRegisterReq
 	}
+	if !c.isActive() {
+		return nil, status.Error(codes.Unavailable, ErrInactiveMaster.Error())
+	}
+
+	c.RLock()
+	members := make([]*Member, len(c.members))
+	copy(members, c.members)
+	c.RUnlock()
 
+	// Notify registered node to update remote services, a broken member should
+	// not prevent the new member from joining
 	resp := &clusterpb.RegisterResponse{}
-	for _, m := range c.members {
+	newMember := &clusterpb.NewMemberRequest{MemberInfo: req.MemberInfo}
+	for _, m := range members {
 		if m.memberInfo.ServiceAddr == req.MemberInfo.ServiceAddr {
-			return nil, fmt.Errorf("address %s has registered", req.MemberInfo.ServiceAddr)
+			continue
 		}
-	}
-
-	// Notify registered node to update remote services
-	newMember := &clusterpb.NewMemberRequest{MemberInfo: req.MemberInfo}
-	for _, m := range c.members {
 		resp.Members = append(resp.Members, m.memberInfo)
 		if m.isMaster {
 			continue
 		}
 		pool, err := c.rpcClient.getConnPool(m.memberInfo.ServiceAddr)
 		if err != nil {
-			return nil, err
+			log.Println("Cannot retrieve connection pool for address", m.memberInfo.ServiceAddr, err)
+			continue
 		}
 		client := clusterpb.NewMemberClient(pool.Get())
 		_, err = client.NewMember(context.Background(), newMember)
 		if err != nil {
-			return nil, err
+			log.Println("Notify member to add address failed", m.memberInfo.ServiceAddr, req.MemberInfo.ServiceAddr, err)
 		}
 	}
 
 	log.Println("New peer register to cluster", req.MemberInfo.ServiceAddr)
 
-	// Register services to current node
-	c.currentNode.handler.addRemoteService(req.MemberInfo)
 	c.Lock()
-	c.members = append(c.members, &Member{isMaster: false, memberInfo: req.MemberInfo})
+	var found bool
+	for _, m := range c.members {
+		if m.memberInfo.ServiceAddr == req.MemberInfo.ServiceAddr {
+			m.memberInfo = req.MemberInfo
+			m.lastHeartbeatAt = time.Now()
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.members = append(c.members, &Member{
+			isMaster:        false,
+			memberInfo:      req.MemberInfo,
+			lastHeartbeatAt: time.Now(),
+		})
+	}
 	c.Unlock()
+
+	// Register services and routes to current node like other members
+	c.currentNode.onMemberEvent(MemberEvent{Type: MemberAdded, Member: req.MemberInfo})
 	return resp, nil
 }
 
-// Register implements the MasterServer gRPC service
+// Unregister implements the MasterServer gRPC service
 func (c *cluster) Unregister(_ context.Context, req *clusterpb.UnregisterRequest) (*clusterpb.UnregisterResponse, error) {
 	if req.ServiceAddr == "" {
 		return nil, ErrInvalidRegisterReq
 	}
+	if !c.isActive() {
+		return nil, status.Error(codes.Unavailable, ErrInactiveMaster.Error())
+	}
+
+	if err := c.unregister(req.ServiceAddr); err != nil {
+		return nil, err
+	}
+
+	log.Println("Exists peer unregister to cluster", req.ServiceAddr)
+	return &clusterpb.UnregisterResponse{}, nil
+}
+
+// Heartbeat implements the MasterServer gRPC service, members call it periodically
+// to renew their lease in the cluster
+func (c *cluster) Heartbeat(_ context.Context, req *clusterpb.HeartbeatRequest) (*clusterpb.HeartbeatResponse, error) {
+	if !c.isActive() {
+		return nil, status.Error(codes.Unavailable, ErrInactiveMaster.Error())
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, m := range c.members {
+		if m.memberInfo.ServiceAddr == req.ServiceAddr {
+			m.lastHeartbeatAt = time.Now()
+			return &clusterpb.HeartbeatResponse{}, nil
+		}
+	}
+
+	// The member should register again, e.g: the master has been restarted
+	return nil, status.Errorf(codes.NotFound, "address %s has not registered", req.ServiceAddr)
+}
+
+// activate makes the current node serve as master, all known members will be
+// treated as alive at the moment
+func (c *cluster) activate(self *clusterpb.MemberInfo) {
+	c.Lock()
+	now := time.Now()
+	for _, m := range c.members {
+		m.lastHeartbeatAt = now
+	}
+	c.members = append(c.members, &Member{isMaster: true, memberInfo: self})
+	c.Unlock()
+	atomic.StoreInt32(&c.active, 1)
+}
 
+// updateMaster updates the member information of current master node and notifies
+// all other members
+func (c *cluster) updateMaster(self *clusterpb.MemberInfo) {
+	c.Lock()
+	var addrs []string
+	for _, m := range c.members {
+		if m.isMaster {
+			m.memberInfo = self
+			continue
+		}
+		addrs = append(addrs, m.memberInfo.ServiceAddr)
+	}
+	c.Unlock()
+
+	request := &clusterpb.NewMemberRequest{MemberInfo: self}
+	for _, addr := range addrs {
+		pool, err := c.rpcClient.getConnPool(addr)
+		if err != nil {
+			log.Println("Cannot retrieve connection pool for address", addr, err)
+			continue
+		}
+		client := clusterpb.NewMemberClient(pool.Get())
+		if _, err := client.NewMember(context.Background(), request); err != nil {
+			log.Println("Notify member to update address failed", addr, err)
+		}
+	}
+}
+
+func (c *cluster) isActive() bool {
+	return atomic.LoadInt32(&c.active) == 1
+}
+
+// unregister removes the member from cluster and notifies all other members
+// to remove the services provided by it
+func (c *cluster) unregister(addr string) error {
+	c.Lock()
 	var index = -1
-	resp := &clusterpb.UnregisterResponse{}
 	for i, m := range c.members {
-		if m.memberInfo.ServiceAddr == req.ServiceAddr {
+		if m.memberInfo.ServiceAddr == addr {
 			index = i
 			break
 		}
 	}
 	if index < 0 {
-		return nil, fmt.Errorf("address %s has  notregistered", req.ServiceAddr)
+		c.Unlock()
+		return fmt.Errorf("address %s has not registered", addr)
 	}
+	c.members = append(c.members[:index], c.members[index+1:]...)
+	members := make([]*Member, len(c.members))
+	copy(members, c.members)
+	c.Unlock()
 
-	// Notify registered node to update remote services
-	delMember := &clusterpb.DelMemberRequest{ServiceAddr: req.ServiceAddr}
-	for _, m := range c.members {
-		if m.MemberInfo().ServiceAddr == c.currentNode.ServiceAddr {
+	// Notify registered node to update remote services, a broken member should
+	// not prevent the others from being notified
+	delMember := &clusterpb.DelMemberRequest{ServiceAddr: addr}
+	for _, m := range members {
+		if m.isMaster {
 			continue
 		}
 		pool, err := c.rpcClient.getConnPool(m.memberInfo.ServiceAddr)
 		if err != nil {
-			return nil, err
+			log.Println("Cannot retrieve connection pool for address", m.memberInfo.ServiceAddr, err)
+			continue
 		}
 		client := clusterpb.NewMemberClient(pool.Get())
 		_, err = client.DelMember(context.Background(), delMember)
 		if err != nil {
-			return nil, err
+			log.Println("Notify member to delete address failed", m.memberInfo.ServiceAddr, addr, err)
 		}
 	}
 
-	log.Println("Exists peer unregister to cluster", req.ServiceAddr)
+	// Unregister services from current node
+	c.currentNode.onMemberEvent(MemberEvent{Type: MemberRemoved, Member: &clusterpb.MemberInfo{ServiceAddr: addr}})
+	return nil
+}
 
-	// Register services to current node
-	c.currentNode.handler.delMember(req.ServiceAddr)
-	c.Lock()
-	if index == len(c.members)-1 {
-		c.members = c.members[:index]
-	} else {
-		c.members = append(c.members[:index], c.members[index+1:]...)
+// checkHeartbeat evicts the members which missed too many heartbeats until
+// the current node shutdown
+func (c *cluster) checkHeartbeat(interval time.Duration, maxMissed int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.evictExpired(time.Now().Add(-interval * time.Duration(maxMissed)))
+		case <-c.currentNode.chDie:
+			return
+		}
+	}
+}
+
+// evictExpired evicts all members whose last heartbeat is earlier than deadline
+func (c *cluster) evictExpired(deadline time.Time) {
+	var expired []string
+	c.RLock()
+	for _, m := range c.members {
+		if !m.isMaster && m.lastHeartbeatAt.Before(deadline) {
+			expired = append(expired, m.memberInfo.ServiceAddr)
+		}
+	}
+	c.RUnlock()
+
+	for _, addr := range expired {
+		log.Println("Member heartbeat timeout and will be evicted from cluster", addr)
+		if err := c.unregister(addr); err != nil {
+			log.Println("Evict member failed", addr, err)
+		}
 	}
-	c.Unlock()
-	return resp, nil
 }
 
 func (c *cluster) setRpcClient(client *rpcClient) {
@@ -149,13 +291,43 @@ func (c *cluster) remoteAddrs() []string {
 }
 
 func (c *cluster) initMembers(members []*clusterpb.MemberInfo) {
-	c.Lock()
 	for _, info := range members {
-		c.members = append(c.members, &Member{
-			memberInfo: info,
-		})
+		c.addMember(info)
 	}
-	c.Unlock()
+}
+
+// absentMembers returns the known members which are not contained in members
+func (c *cluster) absentMembers(members []*clusterpb.MemberInfo) []*Member {
+	c.RLock()
+	defer c.RUnlock()
+
+	var absent []*Member
+	for _, m := range c.members {
+		var found bool
+		for _, info := range members {
+			if m.memberInfo.ServiceAddr == info.ServiceAddr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			absent = append(absent, m)
+		}
+	}
+	return absent
+}
+
+// memberInfo returns the member information of the address
+func (c *cluster) memberInfo(addr string) *clusterpb.MemberInfo {
+	c.RLock()
+	defer c.RUnlock()
+
+	for _, m := range c.members {
+		if m.memberInfo.ServiceAddr == addr {
+			return m.memberInfo
+		}
+	}
+	return nil
 }
 
 func (c *cluster) addMember(info *clusterpb.MemberInfo) {
@@ -0,0 +1,587 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/component"
+	"github.com/revzim/nano/errcode"
+	"github.com/revzim/nano/internal/env"
+	"github.com/revzim/nano/internal/message"
+	"github.com/revzim/nano/mock"
+	"github.com/revzim/nano/scheduler"
+	"github.com/revzim/nano/serialize/protobuf"
+	"github.com/revzim/nano/session"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCluster_EvictExpired(t *testing.T) {
+	n := &Node{ServiceAddr: "127.0.0.1:4460", sessions: map[int64]*session.Session{}, directory: newDirectory()}
+	n.cluster = newCluster(n)
+	n.handler = NewHandler(n, nil)
+	n.cluster.setRpcClient(newRPCClient(n.dialOptions()...))
+
+	alive := &clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:4461", Services: []string{"Alive"}}
+	dead := &clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:4462", Services: []string{"Dead"}}
+	n.cluster.members = []*Member{
+		{isMaster: true, memberInfo: &clusterpb.MemberInfo{ServiceAddr: n.ServiceAddr}},
+		{memberInfo: alive, lastHeartbeatAt: time.Now()},
+		{memberInfo: dead, lastHeartbeatAt: time.Now().Add(-time.Minute)},
+	}
+	n.handler.addRemoteService(alive)
+	n.handler.addRemoteService(dead)
+
+	s := session.New(mock.NewNetworkEntity())
+	s.Router().Bind("Dead", dead.ServiceAddr)
+	s.Router().Bind("Alive", alive.ServiceAddr)
+	n.storeSession(s)
+
+	n.cluster.evictExpired(time.Now().Add(-time.Second))
+
+	if addrs := n.cluster.remoteAddrs(); len(addrs) != 2 || addrs[1] != alive.ServiceAddr {
+		t.Fatalf("unexpected members after eviction: %v", addrs)
+	}
+	if services := n.handler.RemoteService(); len(services) != 1 || services[0] != "Alive" {
+		t.Fatalf("unexpected remote services after eviction: %v", services)
+	}
+	if _, found := s.Router().Find("Dead"); found {
+		t.Fatal("router binding of evicted member should be removed")
+	}
+	if addr, _ := s.Router().Find("Alive"); addr != alive.ServiceAddr {
+		t.Fatalf("router binding of alive member should be kept, got %s", addr)
+	}
+}
+
+func TestStaticDiscovery_Reload(t *testing.T) {
+	gate := &clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:4461", Services: []string{"Gate"}}
+	game := &clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:4462", Services: []string{"Game"}}
+	d := NewStaticDiscovery(gate, game).(*staticDiscovery)
+
+	chat := &clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:4463", Services: []string{"Chat"}}
+	events := d.reload([]*clusterpb.MemberInfo{gate, chat})
+	if len(events) != 2 {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	if events[0].Type != MemberRemoved || events[0].Member.ServiceAddr != game.ServiceAddr {
+		t.Fatalf("expect game member removed, got %v", events[0])
+	}
+	if events[1].Type != MemberAdded || events[1].Member.ServiceAddr != chat.ServiceAddr {
+		t.Fatalf("expect chat member added, got %v", events[1])
+	}
+
+	if events := d.reload([]*clusterpb.MemberInfo{gate, chat}); len(events) != 0 {
+		t.Fatalf("unexpected events when member list unchanged: %v", events)
+	}
+
+	// The member restarted with different routes is announced again
+	upgraded := &clusterpb.MemberInfo{ServiceAddr: chat.ServiceAddr, Services: chat.Services, Routes: []string{"Chat.Join"}}
+	events = d.reload([]*clusterpb.MemberInfo{gate, upgraded})
+	if len(events) != 1 || events[0].Type != MemberAdded || events[0].Member != upgraded {
+		t.Fatalf("expect chat member added again, got %v", events)
+	}
+}
+
+func TestDNSDiscovery_Resolve(t *testing.T) {
+	lookup := func(_ context.Context, host string) ([]string, error) {
+		switch host {
+		case "gate.nano":
+			return []string{"10.0.0.2", "10.0.0.1"}, nil
+		case "game.nano":
+			return []string{"10.0.0.1"}, nil
+		}
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+
+	names := map[string]string{"Gate": "gate.nano:4460", "Game": "game.nano:4460"}
+	members, err := resolveMembers(names, lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("unexpected members: %v", members)
+	}
+	if members[0].ServiceAddr != "10.0.0.1:4460" || !reflect.DeepEqual(members[0].Services, []string{"Game", "Gate"}) {
+		t.Fatalf("unexpected member: %v", members[0])
+	}
+	if members[1].ServiceAddr != "10.0.0.2:4460" || !reflect.DeepEqual(members[1].Services, []string{"Gate"}) {
+		t.Fatalf("unexpected member: %v", members[1])
+	}
+
+	names["Chat"] = "chat.nano:4460"
+	if _, err := resolveMembers(names, lookup); err == nil {
+		t.Fatal("expect error when a name cannot be resolved")
+	}
+}
+
+func TestMemoryDiscovery_StopWatch(t *testing.T) {
+	d := NewMemoryDiscovery()
+	var events int
+	stop := d.Watch(func(MemberEvent) { events++ })
+
+	d.Register(&clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:4461"})
+	stop()
+	d.Register(&clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:4462"})
+	if events != 1 {
+		t.Fatalf("expect 1 event before stopped, got %d", events)
+	}
+}
+
+func TestFileDiscovery_StopWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "members.json")
+	write := func(data string) {
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`[{"serviceAddr":"127.0.0.1:4461","services":["Gate"]}]`)
+	d, err := NewFileDiscovery(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chEvent := make(chan MemberEvent, 10)
+	stop := d.Watch(func(event MemberEvent) { chEvent <- event })
+	write(`[{"serviceAddr":"127.0.0.1:4462","services":["Game"]}]`)
+	select {
+	case <-chEvent:
+	case <-time.After(time.Second):
+		t.Fatal("the member file is not reloaded")
+	}
+
+	// No more reloading after stopped
+	stop()
+	time.Sleep(20 * time.Millisecond)
+	for len(chEvent) > 0 {
+		<-chEvent
+	}
+	write(`[{"serviceAddr":"127.0.0.1:4463","services":["Chat"]}]`)
+	select {
+	case event := <-chEvent:
+		t.Fatalf("unexpected event after stopped: %v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNode_DuplicateLogin(t *testing.T) {
+	n := &Node{ServiceAddr: "127.0.0.1:4470", sessions: map[int64]*session.Session{}, directory: newDirectory()}
+	n.announcements = newAnnouncements()
+	n.cluster = newCluster(n)
+	n.DuplicateLogin = RejectNew
+
+	// The uid is claimed asynchronously
+	waitBound := func(uid int64, s *session.Session) {
+		for i := 0; i < 100; i++ {
+			if loc, _ := n.directory.find(uid); loc.sid == s.ID() {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("uid %d should be bound to session %d", uid, s.ID())
+	}
+
+	old := session.New(mock.NewNetworkEntity())
+	if err := n.bindSession(old, 1001, n.ServiceAddr, old.ID()); err != nil {
+		t.Fatalf("bind uid failed: %v", err)
+	}
+	waitBound(1001, old)
+	if err := n.bindSession(old, 1001, n.ServiceAddr, old.ID()); err != nil {
+		t.Fatalf("bind uid to the same session again failed: %v", err)
+	}
+	waitBound(1001, old)
+
+	s := session.New(mock.NewNetworkEntity())
+	if err := n.bindSession(s, 1001, n.ServiceAddr, s.ID()); err != ErrDuplicateLogin {
+		t.Fatalf("expect %v, got %v", ErrDuplicateLogin, err)
+	}
+	if loc, _ := n.directory.find(1001); loc.sid != old.ID() {
+		t.Fatalf("uid should be bound to the old session, got %d", loc.sid)
+	}
+
+	// The uid is bound to the new session at once by AllowMultiple
+	n = &Node{ServiceAddr: "127.0.0.1:4470", sessions: map[int64]*session.Session{}, directory: n.directory}
+	n.announcements = newAnnouncements()
+	n.cluster = newCluster(n)
+	n.DuplicateLogin = AllowMultiple
+	if err := n.bindSession(s, 1001, n.ServiceAddr, s.ID()); err != nil {
+		t.Fatalf("bind uid failed: %v", err)
+	}
+	if loc, _ := n.directory.find(1001); loc.sid != s.ID() {
+		t.Fatalf("uid should be bound to the new session, got %d", loc.sid)
+	}
+}
+
+func TestNode_UIDOwner(t *testing.T) {
+	n := &Node{ServiceAddr: "127.0.0.1:4474", sessions: map[int64]*session.Session{}, directory: newDirectory()}
+	n.cluster = newCluster(n)
+
+	// The owner is chosen from the members without master
+	n.cluster.addMember(&clusterpb.MemberInfo{ServiceAddr: "127.0.0.1:4475"})
+	owners := map[string]bool{}
+	for uid := int64(1); uid <= 10; uid++ {
+		owners[n.uidOwner(uid)] = true
+	}
+	if len(owners) != 2 {
+		t.Fatalf("uids should be owned by all members, got %v", owners)
+	}
+
+	// The master owns all uids while the members join and leave
+	n.discovery = &masterDiscovery{node: n, masterAddr: "127.0.0.1:4476"}
+	for i := 0; i < 3; i++ {
+		for uid := int64(1); uid <= 10; uid++ {
+			if owner := n.uidOwner(uid); owner != "127.0.0.1:4476" {
+				t.Fatalf("uid %d should be owned by master, got %s", uid, owner)
+			}
+		}
+		n.cluster.addMember(&clusterpb.MemberInfo{ServiceAddr: fmt.Sprintf("127.0.0.1:%d", 4477+i)})
+	}
+	n.cluster.delMember("127.0.0.1:4475")
+	if owner := n.uidOwner(1001); owner != "127.0.0.1:4476" {
+		t.Fatalf("uid should be owned by master, got %s", owner)
+	}
+}
+
+func TestDirectory_ClaimCancelled(t *testing.T) {
+	d := newDirectory()
+	loc := sessionLocation{gateAddr: "127.0.0.1:4471", sid: 1}
+
+	d.startClaim(1001, loc)
+	if uid := d.confirm(1001, loc); uid != 1001 {
+		t.Fatalf("claim should be confirmed, got %d", uid)
+	}
+	if bound, _ := d.find(1001); bound != loc {
+		t.Fatalf("uid should be bound to %v, got %v", loc, bound)
+	}
+
+	// The session unbound or closed while claiming
+	d.startClaim(1002, loc)
+	d.unset(1002, loc)
+	if uid := d.confirm(1002, loc); uid != 0 {
+		t.Fatalf("claim should be cancelled by unbinding, got %d", uid)
+	}
+	d.startClaim(1002, loc)
+	d.remove(loc.gateAddr, loc.sid)
+	if uid := d.confirm(1002, loc); uid != 0 {
+		t.Fatalf("claim should be cancelled by closing, got %d", uid)
+	}
+	if _, found := d.find(1002); found {
+		t.Fatalf("uid of cancelled claim should not be bound")
+	}
+
+	// The session bound to another uid while claiming
+	d.startClaim(1003, loc)
+	d.startClaim(1004, loc)
+	if uid := d.confirm(1003, loc); uid != 1004 {
+		t.Fatalf("claim should be replaced, got %d", uid)
+	}
+	if d.cancelClaim(1003, loc) || !d.cancelClaim(1004, loc) {
+		t.Fatalf("only the current claim should be cancelled")
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	d := newDirectory()
+	gate1 := sessionLocation{gateAddr: "127.0.0.1:4471", sid: 1}
+	gate2 := sessionLocation{gateAddr: "127.0.0.1:4472", sid: 1}
+
+	d.set(1001, gate1)
+	d.set(1002, gate2)
+	// The uid is bound to another session, the latest one is found
+	latest := sessionLocation{gateAddr: gate1.gateAddr, sid: 2}
+	d.set(1001, latest)
+	if loc, _ := d.find(1001); loc != latest {
+		t.Fatalf("uid 1001 should be bound to %v, got %v", latest, loc)
+	}
+
+	// The earlier session is found after the latest one closed
+	d.remove(latest.gateAddr, latest.sid)
+	if loc, _ := d.find(1001); loc != gate1 {
+		t.Fatalf("uid 1001 should be bound to %v, got %v", gate1, loc)
+	}
+	d.remove(gate1.gateAddr, gate1.sid)
+	if _, found := d.find(1001); found {
+		t.Fatalf("uid 1001 should be removed with its sessions")
+	}
+	if loc, _ := d.find(1002); loc != gate2 {
+		t.Fatalf("uid 1002 should be bound to %v, got %v", gate2, loc)
+	}
+
+	d.removeGate(gate2.gateAddr)
+	if len(d.sessions) != 0 || len(d.uids) != 0 {
+		t.Fatalf("directory should be empty, got %v %v", d.sessions, d.uids)
+	}
+}
+
+func TestDirectory_Claim(t *testing.T) {
+	d := newDirectory()
+	old := sessionLocation{gateAddr: "127.0.0.1:4471", sid: 1}
+	s := sessionLocation{gateAddr: "127.0.0.1:4472", sid: 1}
+
+	if _, found, err := d.claim(1001, old, RejectNew); found || err != nil {
+		t.Fatalf("claim uid failed: %v %v", found, err)
+	}
+	if _, _, err := d.claim(1001, old, RejectNew); err != nil {
+		t.Fatalf("claim uid by the same session again failed: %v", err)
+	}
+	if prev, _, err := d.claim(1001, s, RejectNew); err != ErrDuplicateLogin || prev != old {
+		t.Fatalf("expect %v with %v, got %v with %v", ErrDuplicateLogin, old, err, prev)
+	}
+
+	// The old session is replaced
+	if prev, found, err := d.claim(1001, s, KickOld); !found || err != nil || prev != old {
+		t.Fatalf("expect %v replaced, got %v %v %v", old, prev, found, err)
+	}
+	d.remove(s.gateAddr, s.sid)
+	if _, found := d.find(1001); found {
+		t.Fatalf("the replaced session should not be found")
+	}
+}
+
+func TestKickPayload(t *testing.T) {
+	serializer := env.Serializer
+	env.Serializer = protobuf.NewSerializer()
+	defer func() { env.Serializer = serializer }()
+
+	// The reason is encoded by the serializer
+	reason := &clusterpb.KickSessionRequest{SessionId: 1}
+	data, err := kickPayload(reason)
+	if err != nil {
+		t.Fatalf("encode kick reason failed: %v", err)
+	}
+	expect, _ := proto.Marshal(reason)
+	if !bytes.Equal(data, expect) {
+		t.Fatalf("expect %v, got %v", expect, data)
+	}
+
+	// The reason cannot be encoded by the serializer is encoded as JSON
+	data, err = kickPayload(&DrainKick{Reason: "draining", Reconnect: true})
+	if err != nil {
+		t.Fatalf("encode kick reason failed: %v", err)
+	}
+	expect, _ = json.Marshal(&DrainKick{Reason: "draining", Reconnect: true})
+	if !bytes.Equal(data, expect) {
+		t.Fatalf("expect %s, got %s", expect, data)
+	}
+}
+
+func TestNode_ConcurrentLogin(t *testing.T) {
+	n := &Node{ServiceAddr: "127.0.0.1:4473", sessions: map[int64]*session.Session{}, directory: newDirectory()}
+	n.cluster = newCluster(n)
+
+	var wg sync.WaitGroup
+	var claimed int32
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(sid int64) {
+			defer wg.Done()
+			loc := sessionLocation{gateAddr: n.ServiceAddr, sid: sid}
+			if _, _, err := n.claim(context.Background(), 1001, loc, RejectNew); err == nil {
+				atomic.AddInt32(&claimed, 1)
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+	if claimed != 1 {
+		t.Fatalf("expect only one session claimed the uid, got %d", claimed)
+	}
+}
+
+type remoteConn struct {
+	net.Conn
+	addr net.Addr
+}
+
+func (c remoteConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+func TestNode_Admit(t *testing.T) {
+	n := &Node{admission: newAdmission()}
+	n.MaxSessionsPerIP = 1
+	conn := func(ip string) net.Conn {
+		return remoteConn{addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 4474}}
+	}
+
+	ip, reason := n.admit(conn("10.0.0.1"))
+	if reason != "" {
+		t.Fatalf("unexpected rejection: %s", reason)
+	}
+	if _, reason := n.admit(conn("10.0.0.1")); reason == "" {
+		t.Fatalf("the connection exceeding the cap should be rejected")
+	}
+	// The connections from other IPs are not affected
+	if _, reason := n.admit(conn("10.0.0.2")); reason != "" {
+		t.Fatalf("unexpected rejection: %s", reason)
+	}
+
+	n.leave(ip)
+	if _, reason := n.admit(conn("10.0.0.1")); reason != "" {
+		t.Fatalf("unexpected rejection after left: %s", reason)
+	}
+}
+
+func TestWaitingCalls(t *testing.T) {
+	w := &callWaiters{calls: map[string]*waitingCall{}}
+	var ran []string
+	task := func(name string) scheduler.Task {
+		return func() { ran = append(ran, name) }
+	}
+
+	outer := w.register("outer")
+	inner := w.register("inner")
+	// The task is queued to the latest call waiting in the chain
+	if !w.schedule([]string{"remote", "outer", "inner"}, task("inner")) {
+		t.Fatalf("task should be queued to the waiting call")
+	}
+	if !w.schedule([]string{"outer", "unknown"}, task("outer")) {
+		t.Fatalf("task should be queued to the waiting call")
+	}
+	for _, task := range w.take(inner) {
+		task()
+	}
+	if len(outer.tasks) != 1 || len(inner.tasks) != 0 {
+		t.Fatalf("expect 1 task of outer call, got %d %d", len(outer.tasks), len(inner.tasks))
+	}
+
+	// The tasks queued are returned when the call finished, and the tasks called
+	// back by the calls finished, e.g: the caller timed out, are not queued
+	for _, task := range w.unregister("inner") {
+		task()
+	}
+	for _, task := range w.unregister("outer") {
+		task()
+	}
+	if w.schedule([]string{"outer", "inner"}, task("finished")) {
+		t.Fatalf("task should not be queued to the finished calls")
+	}
+	if !reflect.DeepEqual(ran, []string{"inner", "outer"}) {
+		t.Fatalf("expect tasks run in order, got %v", ran)
+	}
+}
+
+func TestRespond_Once(t *testing.T) {
+	a := newAgent(nil, &Node{}, nil, nil)
+	s := a.currentSession()
+	msg := &message.Message{Type: message.Request, Route: "Room.Join"}
+
+	// The handler responded and then returned an error
+	a.lastMid = 1
+	if err := s.Response([]byte("joined")); err != nil {
+		t.Fatalf("respond failed: %v", err)
+	}
+	respond(s, 1, msg, nil, errors.New("join failed"), false)
+	if len(a.chSend) != 1 {
+		t.Fatalf("expect the request is replied once, got %d replies", len(a.chSend))
+	}
+
+	// The error of the next request is still replied
+	respond(s, 2, msg, nil, errors.New("join failed"), false)
+	if len(a.chSend) != 2 {
+		t.Fatalf("expect the error is replied, got %d replies", len(a.chSend))
+	}
+	<-a.chSend
+	if m := <-a.chSend; m.mid != 2 || !m.err {
+		t.Fatalf("expect the error response of request 2, got %+v", m)
+	}
+}
+
+func TestSafeInvoke(t *testing.T) {
+	invoker := func(inv *component.Invocation) (interface{}, error) {
+		panic("handler panic")
+	}
+	_, err := safeInvoke(invoker, &component.Invocation{Route: "Room.Join"})
+	if e, ok := err.(*errcode.Error); !ok || e.Code != errcode.Internal {
+		t.Fatalf("expect the panic is converted into an internal error, got %v", err)
+	}
+}
+
+func TestRequestMeta_Timeout(t *testing.T) {
+	meta := requestMeta{deadline: time.Now().Add(time.Second)}
+	timeout := meta.remoteTimeout()
+	if timeout <= 0 || timeout > int64(time.Second) {
+		t.Fatalf("expect the remaining time before the deadline, got %d", timeout)
+	}
+
+	// The deadline is rebuilt by the local clock of receiver
+	remote := metaFromRemote(timeout, nil)
+	if until := time.Until(remote.deadline); until <= 0 || until > time.Second {
+		t.Fatalf("unexpected deadline, %v until the deadline", until)
+	}
+
+	// The exceeded deadline is still exceeded on the receiver
+	expired := requestMeta{deadline: time.Now().Add(-time.Second)}
+	if remote := metaFromRemote(expired.remoteTimeout(), nil); remote.deadline.IsZero() || time.Now().Before(remote.deadline) {
+		t.Fatalf("expect the deadline exceeded, got %v", remote.deadline)
+	}
+
+	if timeout := (requestMeta{}).remoteTimeout(); timeout != 0 {
+		t.Fatalf("expect no timeout, got %d", timeout)
+	}
+	if remote := metaFromRemote(0, nil); !remote.deadline.IsZero() {
+		t.Fatalf("expect no deadline, got %v", remote.deadline)
+	}
+}
+
+func TestAnnouncements(t *testing.T) {
+	a := newAnnouncements()
+
+	// The announcements of the same session are merged
+	a.push(&clusterpb.SessionBoundRequest{GateAddr: "127.0.0.1:4461", SessionId: 1, Uid: 1001})
+	a.push(&clusterpb.SessionBoundRequest{GateAddr: "127.0.0.1:4461", SessionId: 2, Uid: 1002})
+	a.push(&clusterpb.SessionBoundRequest{GateAddr: "127.0.0.1:4461", SessionId: 1, Uid: 1001, Unbind: true})
+	batch := a.take()
+	if len(batch) != 2 || batch[0].SessionId != 1 || !batch[0].Unbind || batch[1].SessionId != 2 {
+		t.Fatalf("unexpected announcements: %v", batch)
+	}
+	if batch := a.take(); len(batch) != 0 {
+		t.Fatalf("the queue should be cleared, got %v", batch)
+	}
+
+	// The oldest announcement is dropped instead of blocking if the queue is full
+	for i := 0; i <= announceQueueSize; i++ {
+		a.push(&clusterpb.SessionBoundRequest{GateAddr: "127.0.0.1:4461", SessionId: int64(i), Uid: 1001})
+	}
+	batch = a.take()
+	if len(batch) != announceQueueSize || batch[0].SessionId != 1 {
+		t.Fatalf("expect the oldest announcement dropped, got %d announcements", len(batch))
+	}
+}
+
+func TestNode_BindVersion(t *testing.T) {
+	n := &Node{ServiceAddr: "127.0.0.1:4474", sessions: map[int64]*session.Session{}, directory: newDirectory()}
+	n.announcements = newAnnouncements()
+	n.cluster = newCluster(n)
+
+	a := &acceptor{sid: 1, gateAddr: "127.0.0.1:4461", node: n}
+	s := session.New(a)
+	a.session = s
+	if err := s.Bind(1001); err != nil {
+		t.Fatalf("bind uid failed: %v", err)
+	}
+
+	// The message forwarded before the gate saw the binding does not reset the uid
+	if err := n.synchronize(s, 0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := component.Authenticated.Check(s); err != nil {
+		t.Fatalf("the uid bound by backend should be kept, got %v", err)
+	}
+
+	// The uid changed by gate after it saw the binding is synchronized
+	n.synchronize(s, 1001, 1, nil)
+	n.synchronize(s, 0, 2, nil)
+	if s.UID() != 0 {
+		t.Fatalf("expect the uid unbound by gate, got %d", s.UID())
+	}
+}
@@ -20,6 +20,7 @@ const _ = grpc.SupportPackageIsVersion7
 type MasterClient interface {
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
 	Unregister(ctx context.Context, in *UnregisterRequest, opts ...grpc.CallOption) (*UnregisterResponse, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
 }
 
 type masterClient struct {
@@ -48,12 +49,22 @@ func (c *masterClient) Unregister(ctx context.Context, in *UnregisterRequest, op
 	return out, nil
 }
 
+func (c *masterClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Master/Heartbeat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MasterServer is the server API for Master service.
 // All implementations should embed UnimplementedMasterServer
 // for forward compatibility
 type MasterServer interface {
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
 	Unregister(context.Context, *UnregisterRequest) (*UnregisterResponse, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
 }
 
 // UnimplementedMasterServer should be embedded to have forward compatible implementations.
@@ -66,6 +77,9 @@ func (UnimplementedMasterServer) Register(context.Context, *RegisterRequest) (*R
 func (UnimplementedMasterServer) Unregister(context.Context, *UnregisterRequest) (*UnregisterResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Unregister not implemented")
 }
+func (UnimplementedMasterServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
 
 // UnsafeMasterServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to MasterServer will
@@ -114,6 +128,24 @@ func _Master_Unregister_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Master_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MasterServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Master/Heartbeat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MasterServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Master_ServiceDesc is the grpc.ServiceDesc for Master service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -129,6 +161,10 @@ var Master_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Unregister",
 			Handler:    _Master_Unregister_Handler,
 		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Master_Heartbeat_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cluster.proto",
@@ -142,10 +178,18 @@ type MemberClient interface {
 	HandleNotify(ctx context.Context, in *NotifyMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
 	HandlePush(ctx context.Context, in *PushMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
 	HandleResponse(ctx context.Context, in *ResponseMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
+	HandleMulticast(ctx context.Context, in *MulticastMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
+	HandleSync(ctx context.Context, in *SyncMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error)
+	HandleCall(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error)
+	Stream(ctx context.Context, opts ...grpc.CallOption) (Member_StreamClient, error)
 	NewMember(ctx context.Context, in *NewMemberRequest, opts ...grpc.CallOption) (*NewMemberResponse, error)
 	DelMember(ctx context.Context, in *DelMemberRequest, opts ...grpc.CallOption) (*DelMemberResponse, error)
 	SessionClosed(ctx context.Context, in *SessionClosedRequest, opts ...grpc.CallOption) (*SessionClosedResponse, error)
 	CloseSession(ctx context.Context, in *CloseSessionRequest, opts ...grpc.CallOption) (*CloseSessionResponse, error)
+	KickSession(ctx context.Context, in *KickSessionRequest, opts ...grpc.CallOption) (*KickSessionResponse, error)
+	SessionBound(ctx context.Context, in *SessionBoundRequest, opts ...grpc.CallOption) (*SessionBoundResponse, error)
+	FindSession(ctx context.Context, in *FindSessionRequest, opts ...grpc.CallOption) (*FindSessionResponse, error)
+	ClaimSession(ctx context.Context, in *ClaimSessionRequest, opts ...grpc.CallOption) (*ClaimSessionResponse, error)
 }
 
 type memberClient struct {
@@ -192,6 +236,64 @@ func (c *memberClient) HandleResponse(ctx context.Context, in *ResponseMessage,
 	return out, nil
 }
 
+func (c *memberClient) HandleMulticast(ctx context.Context, in *MulticastMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error) {
+	out := new(MemberHandleResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/HandleMulticast", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) HandleSync(ctx context.Context, in *SyncMessage, opts ...grpc.CallOption) (*MemberHandleResponse, error) {
+	out := new(MemberHandleResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/HandleSync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) HandleCall(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error) {
+	out := new(CallResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/HandleCall", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) Stream(ctx context.Context, opts ...grpc.CallOption) (Member_StreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Member_ServiceDesc.Streams[0], "/clusterpb.Member/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &memberStreamClient{stream}
+	return x, nil
+}
+
+type Member_StreamClient interface {
+	Send(*StreamMessage) error
+	Recv() (*StreamMessage, error)
+	grpc.ClientStream
+}
+
+type memberStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *memberStreamClient) Send(m *StreamMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *memberStreamClient) Recv() (*StreamMessage, error) {
+	m := new(StreamMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *memberClient) NewMember(ctx context.Context, in *NewMemberRequest, opts ...grpc.CallOption) (*NewMemberResponse, error) {
 	out := new(NewMemberResponse)
 	err := c.cc.Invoke(ctx, "/clusterpb.Member/NewMember", in, out, opts...)
@@ -228,6 +330,42 @@ func (c *memberClient) CloseSession(ctx context.Context, in *CloseSessionRequest
 	return out, nil
 }
 
+func (c *memberClient) KickSession(ctx context.Context, in *KickSessionRequest, opts ...grpc.CallOption) (*KickSessionResponse, error) {
+	out := new(KickSessionResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/KickSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) SessionBound(ctx context.Context, in *SessionBoundRequest, opts ...grpc.CallOption) (*SessionBoundResponse, error) {
+	out := new(SessionBoundResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/SessionBound", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) FindSession(ctx context.Context, in *FindSessionRequest, opts ...grpc.CallOption) (*FindSessionResponse, error) {
+	out := new(FindSessionResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/FindSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *memberClient) ClaimSession(ctx context.Context, in *ClaimSessionRequest, opts ...grpc.CallOption) (*ClaimSessionResponse, error) {
+	out := new(ClaimSessionResponse)
+	err := c.cc.Invoke(ctx, "/clusterpb.Member/ClaimSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MemberServer is the server API for Member service.
 // All implementations should embed UnimplementedMemberServer
 // for forward compatibility
@@ -236,10 +374,18 @@ type MemberServer interface {
 	HandleNotify(context.Context, *NotifyMessage) (*MemberHandleResponse, error)
 	HandlePush(context.Context, *PushMessage) (*MemberHandleResponse, error)
 	HandleResponse(context.Context, *ResponseMessage) (*MemberHandleResponse, error)
+	HandleMulticast(context.Context, *MulticastMessage) (*MemberHandleResponse, error)
+	HandleSync(context.Context, *SyncMessage) (*MemberHandleResponse, error)
+	HandleCall(context.Context, *CallRequest) (*CallResponse, error)
+	Stream(Member_StreamServer) error
 	NewMember(context.Context, *NewMemberRequest) (*NewMemberResponse, error)
 	DelMember(context.Context, *DelMemberRequest) (*DelMemberResponse, error)
 	SessionClosed(context.Context, *SessionClosedRequest) (*SessionClosedResponse, error)
 	CloseSession(context.Context, *CloseSessionRequest) (*CloseSessionResponse, error)
+	KickSession(context.Context, *KickSessionRequest) (*KickSessionResponse, error)
+	SessionBound(context.Context, *SessionBoundRequest) (*SessionBoundResponse, error)
+	FindSession(context.Context, *FindSessionRequest) (*FindSessionResponse, error)
+	ClaimSession(context.Context, *ClaimSessionRequest) (*ClaimSessionResponse, error)
 }
 
 // UnimplementedMemberServer should be embedded to have forward compatible implementations.
@@ -258,6 +404,18 @@ func (UnimplementedMemberServer) HandlePush(context.Context, *PushMessage) (*Mem
 func (UnimplementedMemberServer) HandleResponse(context.Context, *ResponseMessage) (*MemberHandleResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HandleResponse not implemented")
 }
+func (UnimplementedMemberServer) HandleMulticast(context.Context, *MulticastMessage) (*MemberHandleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleMulticast not implemented")
+}
+func (UnimplementedMemberServer) HandleSync(context.Context, *SyncMessage) (*MemberHandleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleSync not implemented")
+}
+func (UnimplementedMemberServer) HandleCall(context.Context, *CallRequest) (*CallResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleCall not implemented")
+}
+func (UnimplementedMemberServer) Stream(Member_StreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
 func (UnimplementedMemberServer) NewMember(context.Context, *NewMemberRequest) (*NewMemberResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method NewMember not implemented")
 }
@@ -270,6 +428,18 @@ func (UnimplementedMemberServer) SessionClosed(context.Context, *SessionClosedRe
 func (UnimplementedMemberServer) CloseSession(context.Context, *CloseSessionRequest) (*CloseSessionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CloseSession not implemented")
 }
+func (UnimplementedMemberServer) KickSession(context.Context, *KickSessionRequest) (*KickSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KickSession not implemented")
+}
+func (UnimplementedMemberServer) SessionBound(context.Context, *SessionBoundRequest) (*SessionBoundResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SessionBound not implemented")
+}
+func (UnimplementedMemberServer) FindSession(context.Context, *FindSessionRequest) (*FindSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindSession not implemented")
+}
+func (UnimplementedMemberServer) ClaimSession(context.Context, *ClaimSessionRequest) (*ClaimSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClaimSession not implemented")
+}
 
 // UnsafeMemberServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to MemberServer will
@@ -354,6 +524,86 @@ func _Member_HandleResponse_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Member_HandleMulticast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MulticastMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).HandleMulticast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/HandleMulticast",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).HandleMulticast(ctx, req.(*MulticastMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_HandleSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).HandleSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/HandleSync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).HandleSync(ctx, req.(*SyncMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_HandleCall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).HandleCall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/HandleCall",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).HandleCall(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MemberServer).Stream(&memberStreamServer{stream})
+}
+
+type Member_StreamServer interface {
+	Send(*StreamMessage) error
+	Recv() (*StreamMessage, error)
+	grpc.ServerStream
+}
+
+type memberStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *memberStreamServer) Send(m *StreamMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *memberStreamServer) Recv() (*StreamMessage, error) {
+	m := new(StreamMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Member_NewMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(NewMemberRequest)
 	if err := dec(in); err != nil {
@@ -426,6 +676,78 @@ func _Member_CloseSession_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Member_KickSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KickSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).KickSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/KickSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).KickSession(ctx, req.(*KickSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_SessionBound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionBoundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).SessionBound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/SessionBound",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).SessionBound(ctx, req.(*SessionBoundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_FindSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).FindSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/FindSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).FindSession(ctx, req.(*FindSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Member_ClaimSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClaimSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MemberServer).ClaimSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/clusterpb.Member/ClaimSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MemberServer).ClaimSession(ctx, req.(*ClaimSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Member_ServiceDesc is the grpc.ServiceDesc for Member service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -449,6 +771,18 @@ var Member_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HandleResponse",
 			Handler:    _Member_HandleResponse_Handler,
 		},
+		{
+			MethodName: "HandleMulticast",
+			Handler:    _Member_HandleMulticast_Handler,
+		},
+		{
+			MethodName: "HandleSync",
+			Handler:    _Member_HandleSync_Handler,
+		},
+		{
+			MethodName: "HandleCall",
+			Handler:    _Member_HandleCall_Handler,
+		},
 		{
 			MethodName: "NewMember",
 			Handler:    _Member_NewMember_Handler,
@@ -465,7 +799,30 @@ var Member_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CloseSession",
 			Handler:    _Member_CloseSession_Handler,
 		},
+		{
+			MethodName: "KickSession",
+			Handler:    _Member_KickSession_Handler,
+		},
+		{
+			MethodName: "SessionBound",
+			Handler:    _Member_SessionBound_Handler,
+		},
+		{
+			MethodName: "FindSession",
+			Handler:    _Member_FindSession_Handler,
+		},
+		{
+			MethodName: "ClaimSession",
+			Handler:    _Member_ClaimSession_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _Member_Stream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "cluster.proto",
 }
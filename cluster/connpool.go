@@ -27,7 +27,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/revzim/nano/internal/env"
 	"google.golang.org/grpc"
 )
 
@@ -40,26 +39,27 @@ type rpcClient struct {
 	sync.RWMutex
 	isClosed bool
 	pools    map[string]*connPool
+	dialOpts []grpc.DialOption
 }
 
-func newConnArray(maxSize uint, addr string) (*connPool, error) {
+func newConnArray(maxSize uint, addr string, opts []grpc.DialOption) (*connPool, error) {
 	a := &connPool{
 		index: 0,
 		v:     make([]*grpc.ClientConn, maxSize),
 	}
-	if err := a.init(addr); err != nil {
+	if err := a.init(addr, opts); err != nil {
 		return nil, err
 	}
 	return a, nil
 }
 
-func (a *connPool) init(addr string) error {
+func (a *connPool) init(addr string, opts []grpc.DialOption) error {
 	for i := range a.v {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		conn, err := grpc.DialContext(
 			ctx,
 			addr,
-			env.GrpcOptions...,
+			opts...,
 		)
 		cancel()
 		if err != nil {
@@ -90,9 +90,10 @@ func (a *connPool) Close() {
 	}
 }
 
-func newRPCClient() *rpcClient {
+func newRPCClient(opts ...grpc.DialOption) *rpcClient {
 	return &rpcClient{
-		pools: make(map[string]*connPool),
+		pools:    make(map[string]*connPool),
+		dialOpts: opts,
 	}
 }
 
@@ -121,7 +122,7 @@ func (c *rpcClient) createConnPool(addr string) (*connPool, error) {
 	if !ok {
 		var err error
 		// TODO: make conn count configurable
-		array, err = newConnArray(10, addr)
+		array, err = newConnArray(10, addr, c.dialOpts)
 		if err != nil {
 			return nil, err
 		}
@@ -27,3 +27,7 @@ const (
 	statusWorking
 	statusClosed
 )
+
+const (
+	defaultMaxMissedHeartbeats = 3
+)
@@ -0,0 +1,140 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/revzim/nano/session"
+)
+
+// requestMeta is the deadline and trace metadata carried by a request across the
+// cluster, which are applied to the context passed to handler
+type requestMeta struct {
+	deadline time.Time         // zero if the request has no deadline
+	metadata map[string]string // see session.WithMetadata
+	chain    []string          // see callChain
+}
+
+// callChainKey is the context key of the call chain
+type callChainKey struct{}
+
+// scheduledKey is the context key which marks the handler running on the global
+// scheduler, see Node.Call
+type scheduledKey struct{}
+
+// processID identifies current process in the ids of calls, all members in the
+// same process share the global scheduler
+var processID = newProcessID()
+
+// callSeq is the sequence of the calls made in the global scheduler
+var callSeq uint64
+
+func newProcessID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// newCallID returns a unique id of the call made in the global scheduler
+func newCallID() string {
+	return fmt.Sprintf("%s-%d", processID, atomic.AddUint64(&callSeq, 1))
+}
+
+// callChain returns the ids of the calls waited by the schedulers in cluster, which
+// leads to the call made with ctx, see waitingCalls
+func callChain(ctx context.Context) []string {
+	chain, _ := ctx.Value(callChainKey{}).([]string)
+	return chain
+}
+
+func metaFromContext(ctx context.Context) requestMeta {
+	deadline, _ := ctx.Deadline()
+	return requestMeta{deadline: deadline, metadata: session.MetadataFromContext(ctx), chain: callChain(ctx)}
+}
+
+// scheduledContext returns whether ctx is the context of handler running on the
+// global scheduler
+func scheduledContext(ctx context.Context) bool {
+	scheduled, _ := ctx.Value(scheduledKey{}).(bool)
+	return scheduled
+}
+
+// metaFromRemote returns the requestMeta of the message from members, the deadline
+// is rebuilt from the remaining time by the local clock, so that it is not affected
+// by the clock skew between members
+func metaFromRemote(timeout int64, metadata map[string]string) requestMeta {
+	meta := requestMeta{metadata: metadata}
+	if timeout != 0 {
+		meta.deadline = time.Now().Add(time.Duration(timeout))
+	}
+	return meta
+}
+
+// remoteTimeout returns the remaining nanoseconds before the deadline sent to
+// members, it returns 0 if the request has no deadline and a negative value if
+// the deadline has exceeded
+func (m requestMeta) remoteTimeout() int64 {
+	if m.deadline.IsZero() {
+		return 0
+	}
+	if timeout := time.Until(m.deadline); timeout != 0 {
+		return int64(timeout)
+	}
+	return -1
+}
+
+// clientMeta returns the requestMeta of the message from client, the deadline
+// is set by RequestTimeout
+func (n *Node) clientMeta() requestMeta {
+	if n.RequestTimeout <= 0 {
+		return requestMeta{}
+	}
+	return requestMeta{deadline: time.Now().Add(n.RequestTimeout)}
+}
+
+// handlerContext returns the context passed to handler, which is derived from the
+// session context so that it is cancelled when the session closed. The context is
+// marked if the handler runs on the global scheduler.
+func handlerContext(s *session.Session, meta requestMeta, scheduled bool) (context.Context, context.CancelFunc) {
+	ctx := s.Context()
+	if len(meta.metadata) > 0 {
+		ctx = session.WithMetadata(ctx, meta.metadata)
+	}
+	if len(meta.chain) > 0 {
+		ctx = context.WithValue(ctx, callChainKey{}, meta.chain)
+	}
+	if scheduled {
+		ctx = context.WithValue(ctx, scheduledKey{}, true)
+	}
+	if !meta.deadline.IsZero() {
+		return context.WithDeadline(ctx, meta.deadline)
+	}
+	return context.WithCancel(ctx)
+}
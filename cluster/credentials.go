@@ -0,0 +1,134 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+
+	"github.com/revzim/nano/internal/env"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// clusterTokenKey is the metadata key of the pre-shared cluster token
+const clusterTokenKey = "nano-cluster-token"
+
+// NewClusterTLSConfig returns a TLS config for the gRPC traffic between cluster
+// members, each member presents the certificate to the peer and verifies the
+// certificate of peer with the CA certificate. The certificate of a member should
+// contain the host of its service address(IP or DNS name) as subject alternative name.
+func NewClusterTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("failed to append CA certificate: " + caFile)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// serverOptions returns the options of the gRPC server of current node
+func (n *Node) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if n.ClusterTLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(n.ClusterTLS)))
+	}
+	if n.ClusterToken != "" {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(n.unaryTokenInterceptor),
+			grpc.ChainStreamInterceptor(n.streamTokenInterceptor))
+	}
+	return opts
+}
+
+// dialOptions returns the options to dial other members. The cluster TLS config
+// takes precedence over the user options, otherwise the transport credentials
+// passed by user options are kept and the connection is insecure by default.
+func (n *Node) dialOptions() []grpc.DialOption {
+	opts := make([]grpc.DialOption, 0, len(env.GrpcOptions)+3)
+	if n.ClusterTLS == nil {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	opts = append(opts, env.GrpcOptions...)
+	if n.ClusterTLS != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(n.ClusterTLS)))
+	}
+	if n.ClusterToken != "" {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(n.unaryTokenClientInterceptor),
+			grpc.WithChainStreamInterceptor(n.streamTokenClientInterceptor))
+	}
+	return opts
+}
+
+func (n *Node) checkToken(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(clusterTokenKey)
+	if len(tokens) == 0 || subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(n.ClusterToken)) != 1 {
+		return status.Error(codes.Unauthenticated, ErrInvalidClusterToken.Error())
+	}
+	return nil
+}
+
+func (n *Node) unaryTokenInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := n.checkToken(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (n *Node) streamTokenInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := n.checkToken(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (n *Node) unaryTokenClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, clusterTokenKey, n.ClusterToken)
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (n *Node) streamTokenClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, clusterTokenKey, n.ClusterToken)
+	return streamer(ctx, desc, cc, method, opts...)
+}
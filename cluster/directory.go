@@ -0,0 +1,562 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/internal/log"
+	"github.com/revzim/nano/internal/message"
+	"github.com/revzim/nano/session"
+)
+
+// sessionLocation represents where a session lives in cluster
+type sessionLocation struct {
+	gateAddr string
+	sid      int64
+}
+
+// directoryTimeout is the deadline of the calls between members made to claim and
+// find the uids, e.g: asking the owner to claim a uid
+const directoryTimeout = 5 * time.Second
+
+// announceQueueSize is the max count of the sessions whose binding announcements
+// are pending, the oldest one is dropped if exceeded
+const announceQueueSize = 1024
+
+// directory maps the uid to the session locations in cluster, the gates announce
+// the binding to all members when a uid bound to a session. A uid can be bound to
+// multiple sessions with AllowMultiple, the latest session is used to find the uid.
+type directory struct {
+	sync.RWMutex
+	sessions map[int64][]sessionLocation // uid to session locations, the latest last
+	uids     map[sessionLocation]int64   // session location to uid
+	claiming map[sessionLocation]int64   // session location to uid being claimed, see Node.claimSession
+}
+
+func newDirectory() *directory {
+	return &directory{
+		sessions: map[int64][]sessionLocation{},
+		uids:     map[sessionLocation]int64{},
+		claiming: map[sessionLocation]int64{},
+	}
+}
+
+func (d *directory) set(uid int64, loc sessionLocation) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.bind(uid, loc)
+}
+
+// claim binds the uid to the location according to the policy, it returns the
+// latest location bound to the uid before if found. The binding is rejected with
+// ErrDuplicateLogin by RejectNew, and the location found is removed by KickOld.
+func (d *directory) claim(uid int64, loc sessionLocation, policy DuplicateLoginPolicy) (sessionLocation, bool, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	locs := d.sessions[uid]
+	if len(locs) == 0 || locs[len(locs)-1] == loc {
+		d.bind(uid, loc)
+		return sessionLocation{}, false, nil
+	}
+
+	prev := locs[len(locs)-1]
+	switch policy {
+	case RejectNew:
+		return prev, true, ErrDuplicateLogin
+	case KickOld:
+		d.delete(uid, prev)
+	}
+	d.bind(uid, loc)
+	return prev, true, nil
+}
+
+// startClaim records the uid being claimed by the session of current node, the
+// claim is cancelled if the session unbound or closed before confirmed
+func (d *directory) startClaim(uid int64, loc sessionLocation) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.claiming[loc] = uid
+}
+
+// confirm binds the uid claimed by the session, it returns the uid which the session
+// is claiming now, the binding is confirmed only if it equals to the uid. It returns
+// 0 if the session has been unbound or closed while claiming.
+func (d *directory) confirm(uid int64, loc sessionLocation) int64 {
+	d.Lock()
+	defer d.Unlock()
+
+	current := d.claiming[loc]
+	if current == uid {
+		delete(d.claiming, loc)
+		d.bind(uid, loc)
+	}
+	return current
+}
+
+// cancelClaim cancels the claim of uid rejected by the owner, it returns false if
+// the session is not claiming the uid any more
+func (d *directory) cancelClaim(uid int64, loc sessionLocation) bool {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.claiming[loc] != uid {
+		return false
+	}
+	delete(d.claiming, loc)
+	return true
+}
+
+// bind binds the uid to the location as the latest one, the lock should be held
+func (d *directory) bind(uid int64, loc sessionLocation) {
+	if old, found := d.uids[loc]; found {
+		d.delete(old, loc)
+	}
+	d.sessions[uid] = append(d.sessions[uid], loc)
+	d.uids[loc] = uid
+}
+
+// delete removes the binding of the uid and location, the lock should be held
+func (d *directory) delete(uid int64, loc sessionLocation) {
+	locs := d.sessions[uid]
+	for i, l := range locs {
+		if l == loc {
+			locs = append(locs[:i:i], locs[i+1:]...)
+			break
+		}
+	}
+	if len(locs) == 0 {
+		delete(d.sessions, uid)
+	} else {
+		d.sessions[uid] = locs
+	}
+	if u, found := d.uids[loc]; found && u == uid {
+		delete(d.uids, loc)
+	}
+}
+
+func (d *directory) find(uid int64) (sessionLocation, bool) {
+	d.RLock()
+	defer d.RUnlock()
+
+	locs := d.sessions[uid]
+	if len(locs) == 0 {
+		return sessionLocation{}, false
+	}
+	return locs[len(locs)-1], true
+}
+
+// unset removes the location bound to the uid
+func (d *directory) unset(uid int64, loc sessionLocation) {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.claiming[loc] == uid {
+		delete(d.claiming, loc)
+	}
+	d.delete(uid, loc)
+}
+
+// remove removes the uid bound to the session
+func (d *directory) remove(gateAddr string, sid int64) {
+	d.Lock()
+	defer d.Unlock()
+
+	loc := sessionLocation{gateAddr: gateAddr, sid: sid}
+	delete(d.claiming, loc)
+	if uid, found := d.uids[loc]; found {
+		d.delete(uid, loc)
+	}
+}
+
+// removeGate removes all sessions live in the gate
+func (d *directory) removeGate(gateAddr string) {
+	d.Lock()
+	defer d.Unlock()
+
+	for loc, uid := range d.uids {
+		if loc.gateAddr == gateAddr {
+			d.delete(uid, loc)
+		}
+	}
+	for loc := range d.claiming {
+		if loc.gateAddr == gateAddr {
+			delete(d.claiming, loc)
+		}
+	}
+}
+
+// Bind implements the session.Binder interface
+func (a *agent) Bind(uid int64) error {
+	s := a.currentSession()
+	if err := a.node.bindSession(s, uid, a.node.ServiceAddr, s.ID()); err != nil {
+		return err
+	}
+	a.rebound()
+	return nil
+}
+
+// Bind implements the session.Binder interface
+func (a *acceptor) Bind(uid int64) error {
+	if err := a.node.bindSession(a.session, uid, a.gateAddr, a.sid); err != nil {
+		return err
+	}
+	a.rebind(nil)
+	return nil
+}
+
+// Unbind implements the session.Unbinder interface
+func (a *agent) Unbind(uid int64) error {
+	a.node.unbindSession(uid, a.node.ServiceAddr, a.currentSession().ID())
+	a.rebound()
+	return nil
+}
+
+// Unbind implements the session.Unbinder interface
+func (a *acceptor) Unbind(uid int64) error {
+	a.node.unbindSession(uid, a.gateAddr, a.sid)
+	a.rebind(nil)
+	return nil
+}
+
+// bindSession applies the DuplicateLoginPolicy, updates the directory and announces
+// the binding to all members. The binding known by current node is rejected at
+// once, and the uid is claimed from its owner asynchronously so that the caller,
+// usually the scheduler, is not blocked by the members, see Node.claimSession.
+func (n *Node) bindSession(s *session.Session, uid int64, gateAddr string, sid int64) error {
+	loc := sessionLocation{gateAddr: gateAddr, sid: sid}
+	if n.DuplicateLogin == AllowMultiple {
+		n.directory.set(uid, loc)
+		n.announceBinding(&clusterpb.SessionBoundRequest{
+			GateAddr:  gateAddr,
+			SessionId: sid,
+			Uid:       uid,
+		})
+		return nil
+	}
+
+	if n.DuplicateLogin == RejectNew {
+		if prev, found := n.directory.find(uid); found && prev != loc {
+			return ErrDuplicateLogin
+		}
+	}
+	n.directory.startClaim(uid, loc)
+	go n.claimSession(s, uid, loc)
+	return nil
+}
+
+// unbindSession removes the uid from directory and announces the unbinding to
+// all members
+func (n *Node) unbindSession(uid int64, gateAddr string, sid int64) {
+	n.directory.unset(uid, sessionLocation{gateAddr: gateAddr, sid: sid})
+	n.announceBinding(&clusterpb.SessionBoundRequest{
+		GateAddr:  gateAddr,
+		SessionId: sid,
+		Uid:       uid,
+		Unbind:    true,
+	})
+}
+
+// announcements queues the binding announcements, the pending announcements of
+// the same session are merged and only the latest one is sent. Queuing never
+// blocks: the oldest announcement is dropped if the queue is full, the members
+// missing the binding find the uid by asking all members, see Node.locate.
+type announcements struct {
+	sync.Mutex
+	pending map[sessionLocation]*clusterpb.SessionBoundRequest
+	order   []sessionLocation // the sessions of pending announcements in order
+	chReady chan struct{}     // notified when an announcement queued
+}
+
+func newAnnouncements() *announcements {
+	return &announcements{
+		pending: map[sessionLocation]*clusterpb.SessionBoundRequest{},
+		chReady: make(chan struct{}, 1),
+	}
+}
+
+func (a *announcements) push(request *clusterpb.SessionBoundRequest) {
+	a.Lock()
+	loc := sessionLocation{gateAddr: request.GateAddr, sid: request.SessionId}
+	if _, found := a.pending[loc]; !found {
+		if len(a.order) >= announceQueueSize {
+			dropped := a.order[0]
+			log.Println("Announcement queue is full, drop the binding of session", dropped.gateAddr, dropped.sid)
+			delete(a.pending, dropped)
+			a.order = a.order[1:]
+		}
+		a.order = append(a.order, loc)
+	}
+	a.pending[loc] = request
+	a.Unlock()
+
+	select {
+	case a.chReady <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the pending announcements in order and clears the queue
+func (a *announcements) take() []*clusterpb.SessionBoundRequest {
+	a.Lock()
+	defer a.Unlock()
+
+	batch := make([]*clusterpb.SessionBoundRequest, 0, len(a.order))
+	for _, loc := range a.order {
+		batch = append(batch, a.pending[loc])
+	}
+	a.pending = map[sessionLocation]*clusterpb.SessionBoundRequest{}
+	a.order = nil
+	return batch
+}
+
+// announceBinding queues the binding announcement without blocking, which will be
+// sent to all members by the announcing goroutine
+func (n *Node) announceBinding(request *clusterpb.SessionBoundRequest) {
+	n.announcements.push(request)
+}
+
+// announce sends the queued binding announcements to all members until the node
+// shutdown, the pending announcements are sent in batch and the members are
+// announced concurrently
+func (n *Node) announce() {
+	for {
+		select {
+		case <-n.announcements.chReady:
+		case <-n.chDie:
+			return
+		}
+		batch := n.announcements.take()
+		if len(batch) == 0 {
+			continue
+		}
+
+		var wg sync.WaitGroup
+		for _, addr := range n.cluster.remoteAddrs() {
+			if addr == n.ServiceAddr {
+				continue
+			}
+			wg.Add(1)
+			go func(addr string) {
+				defer wg.Done()
+				n.announceTo(addr, batch)
+			}(addr)
+		}
+		wg.Wait()
+	}
+}
+
+// announceTo sends the binding announcements to the member in order
+func (n *Node) announceTo(addr string, batch []*clusterpb.SessionBoundRequest) {
+	for _, request := range batch {
+		var err error
+		if n.StreamTransport {
+			err = n.sendStream(addr, &clusterpb.StreamMessage{
+				Message: &clusterpb.StreamMessage_SessionBound{SessionBound: request},
+			})
+		} else {
+			var pool *connPool
+			pool, err = n.rpcClient.getConnPool(addr)
+			if err == nil {
+				_, err = clusterpb.NewMemberClient(pool.Get()).SessionBound(context.Background(), request)
+			}
+		}
+		if err != nil {
+			log.Println("Announce session binding failed", addr, request.Uid, err)
+		}
+	}
+}
+
+// locate finds the session location of the uid, all members will be asked if
+// the uid is not found in the directory of current node
+func (n *Node) locate(uid int64) (sessionLocation, error) {
+	if loc, found := n.directory.find(uid); found {
+		return loc, nil
+	}
+	if loc, found := n.findRemote(context.Background(), uid); found {
+		n.directory.set(uid, loc)
+		return loc, nil
+	}
+	return sessionLocation{}, ErrUIDNotFound
+}
+
+// findRemote asks all members for the session location of the uid concurrently
+// with the deadline of directoryTimeout, and returns the location found first
+func (n *Node) findRemote(ctx context.Context, uid int64) (sessionLocation, bool) {
+	var addrs []string
+	for _, addr := range n.cluster.remoteAddrs() {
+		if addr != n.ServiceAddr {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, directoryTimeout)
+	request := &clusterpb.FindSessionRequest{Uid: uid}
+	chFound := make(chan sessionLocation, len(addrs))
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			pool, err := n.rpcClient.getConnPool(addr)
+			if err != nil {
+				log.Println("Cannot retrieve connection pool for address", addr, err)
+				return
+			}
+			resp, err := clusterpb.NewMemberClient(pool.Get()).FindSession(ctx, request)
+			if err != nil {
+				log.Println("Find session in remote address failed", addr, err)
+				return
+			}
+			if resp.Found {
+				chFound <- sessionLocation{gateAddr: resp.GateAddr, sid: resp.SessionId}
+			}
+		}(addr)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(chFound)
+	}()
+
+	loc, found := <-chFound
+	return loc, found
+}
+
+// PushToUID pushes the message to the client whose session bound to the uid,
+// the session can live in any gate of cluster
+func (n *Node) PushToUID(uid int64, route string, v interface{}) error {
+	loc, err := n.locate(uid)
+	if err != nil {
+		return err
+	}
+	if loc.gateAddr == n.ServiceAddr {
+		s := n.findSession(loc.sid)
+		if s == nil {
+			return ErrUIDNotFound
+		}
+		return s.Push(route, v)
+	}
+
+	data, err := message.Serialize(v)
+	if err != nil {
+		return err
+	}
+	request := &clusterpb.PushMessage{
+		SessionId: loc.sid,
+		Route:     route,
+		Data:      data,
+	}
+	if n.StreamTransport {
+		return n.sendStream(loc.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Push{Push: request},
+		})
+	}
+	pool, err := n.rpcClient.getConnPool(loc.gateAddr)
+	if err != nil {
+		return err
+	}
+	_, err = clusterpb.NewMemberClient(pool.Get()).HandlePush(context.Background(), request)
+	return err
+}
+
+// KickUID kicks the session bound to the uid with the reason, the session can
+// live in any gate of cluster, see session.Session.Kick
+func (n *Node) KickUID(uid int64, reason interface{}) error {
+	loc, err := n.locate(uid)
+	if err != nil {
+		return err
+	}
+	return n.kickSession(loc, reason)
+}
+
+// kickSession kicks the session which lives in the location with the reason
+func (n *Node) kickSession(loc sessionLocation, reason interface{}) error {
+	if loc.gateAddr == n.ServiceAddr {
+		s := n.findSession(loc.sid)
+		if s == nil {
+			return ErrUIDNotFound
+		}
+		return s.Kick(reason)
+	}
+
+	data, err := kickPayload(reason)
+	if err != nil {
+		return err
+	}
+	request := &clusterpb.KickSessionRequest{SessionId: loc.sid, Data: data}
+	if n.StreamTransport {
+		return n.sendStream(loc.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_KickSession{KickSession: request},
+		})
+	}
+	pool, err := n.rpcClient.getConnPool(loc.gateAddr)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), directoryTimeout)
+	defer cancel()
+	_, err = clusterpb.NewMemberClient(pool.Get()).KickSession(ctx, request)
+	return err
+}
+
+// SessionBound implements the MemberServer interface
+func (n *Node) SessionBound(_ context.Context, req *clusterpb.SessionBoundRequest) (*clusterpb.SessionBoundResponse, error) {
+	loc := sessionLocation{gateAddr: req.GateAddr, sid: req.SessionId}
+	uid := req.Uid
+	if req.Unbind {
+		n.directory.unset(req.Uid, loc)
+		uid = 0
+	} else {
+		n.directory.set(req.Uid, loc)
+	}
+
+	// The uid bound by a member is synchronized to the session of gate and
+	// other backends
+	if s := n.findSessionAt(loc); s != nil {
+		switch v := s.NetworkEntity().(type) {
+		case *agent:
+			s.Synchronize(uid, nil)
+			v.rebound()
+		case *acceptor:
+			v.rebind(func() { s.Synchronize(uid, nil) })
+		default:
+			s.Synchronize(uid, nil)
+		}
+	}
+	return &clusterpb.SessionBoundResponse{}, nil
+}
+
+// FindSession implements the MemberServer interface
+func (n *Node) FindSession(_ context.Context, req *clusterpb.FindSessionRequest) (*clusterpb.FindSessionResponse, error) {
+	loc, found := n.directory.find(req.Uid)
+	return &clusterpb.FindSessionResponse{
+		Found:     found,
+		GateAddr:  loc.gateAddr,
+		SessionId: loc.sid,
+	}, nil
+}
@@ -0,0 +1,363 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/internal/env"
+	"github.com/revzim/nano/internal/log"
+)
+
+// MemberEventType represents the type of a cluster membership change
+type MemberEventType int
+
+const (
+	// MemberAdded indicates a member joined the cluster or updated its information
+	MemberAdded MemberEventType = iota
+	// MemberRemoved indicates a member left the cluster
+	MemberRemoved
+)
+
+// MemberEvent represents a cluster membership change
+type MemberEvent struct {
+	Type   MemberEventType
+	Member *clusterpb.MemberInfo
+}
+
+// Discovery is used to maintain the membership of cluster. The current node registers
+// itself at startup and deregisters at shutdown, the membership changes of other members
+// will be delivered to the function set by Watch until the node stops watching.
+type Discovery interface {
+	// Register registers the member to cluster, registering an existing member
+	// again updates its member information
+	Register(member *clusterpb.MemberInfo) error
+	// Deregister removes the member from cluster
+	Deregister(addr string) error
+	// List returns all members in cluster
+	List() ([]*clusterpb.MemberInfo, error)
+	// Watch sets the function that will be called when membership changes, the
+	// returned function stops watching
+	Watch(fn func(MemberEvent)) (stop func())
+}
+
+// MemoryDiscovery is a Discovery which maintains members in memory, all nodes in
+// the current process sharing the same MemoryDiscovery form a cluster, it is
+// useful to test a cluster in a single process.
+type MemoryDiscovery struct {
+	sync.RWMutex
+	members  []*clusterpb.MemberInfo
+	watchers map[int]func(MemberEvent)
+	nextID   int
+}
+
+// NewMemoryDiscovery returns a new MemoryDiscovery instance
+func NewMemoryDiscovery() *MemoryDiscovery {
+	return &MemoryDiscovery{watchers: map[int]func(MemberEvent){}}
+}
+
+// Register implements the Discovery interface
+func (d *MemoryDiscovery) Register(member *clusterpb.MemberInfo) error {
+	d.Lock()
+	var found bool
+	for i, m := range d.members {
+		if m.ServiceAddr == member.ServiceAddr {
+			d.members[i] = member
+			found = true
+			break
+		}
+	}
+	if !found {
+		d.members = append(d.members, member)
+	}
+	d.Unlock()
+
+	d.notify(MemberEvent{Type: MemberAdded, Member: member})
+	return nil
+}
+
+// Deregister implements the Discovery interface
+func (d *MemoryDiscovery) Deregister(addr string) error {
+	d.Lock()
+	var removed *clusterpb.MemberInfo
+	for i, m := range d.members {
+		if m.ServiceAddr == addr {
+			removed = m
+			d.members = append(d.members[:i], d.members[i+1:]...)
+			break
+		}
+	}
+	d.Unlock()
+
+	if removed != nil {
+		d.notify(MemberEvent{Type: MemberRemoved, Member: removed})
+	}
+	return nil
+}
+
+// List implements the Discovery interface
+func (d *MemoryDiscovery) List() ([]*clusterpb.MemberInfo, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	members := make([]*clusterpb.MemberInfo, len(d.members))
+	copy(members, d.members)
+	return members, nil
+}
+
+// Watch implements the Discovery interface
+func (d *MemoryDiscovery) Watch(fn func(MemberEvent)) func() {
+	d.Lock()
+	defer d.Unlock()
+
+	id := d.nextID
+	d.nextID++
+	d.watchers[id] = fn
+	return func() {
+		d.Lock()
+		defer d.Unlock()
+		delete(d.watchers, id)
+	}
+}
+
+func (d *MemoryDiscovery) notify(event MemberEvent) {
+	d.RLock()
+	ids := make([]int, 0, len(d.watchers))
+	for id := range d.watchers {
+		ids = append(ids, id)
+	}
+	// The watchers are notified in the order of watching
+	sort.Ints(ids)
+	watchers := make([]func(MemberEvent), 0, len(ids))
+	for _, id := range ids {
+		watchers = append(watchers, d.watchers[id])
+	}
+	d.RUnlock()
+
+	for _, fn := range watchers {
+		fn(event)
+	}
+}
+
+// staticDiscovery is a Discovery whose members are listed in advance, the
+// member list can be reloaded periodically from a JSON file or DNS
+type staticDiscovery struct {
+	sync.RWMutex
+	members  []*clusterpb.MemberInfo
+	load     func() ([]*clusterpb.MemberInfo, error)
+	source   string
+	interval time.Duration
+}
+
+// NewStaticDiscovery returns a Discovery with a fixed member list, every node
+// in cluster should be configured with the same member list
+func NewStaticDiscovery(members ...*clusterpb.MemberInfo) Discovery {
+	return &staticDiscovery{members: members}
+}
+
+// NewFileDiscovery returns a Discovery whose member list is loaded from a JSON file,
+// e.g: [{"label":"chat","serviceAddr":"127.0.0.1:34580","services":["RoomService"]}].
+// The file will be reloaded every interval if the interval is positive, so the
+// membership can be changed by the deployment tools by rewriting the file.
+func NewFileDiscovery(path string, interval time.Duration) (Discovery, error) {
+	members, err := loadMembers(path)
+	if err != nil {
+		return nil, err
+	}
+	load := func() ([]*clusterpb.MemberInfo, error) {
+		return loadMembers(path)
+	}
+	return &staticDiscovery{members: members, load: load, source: path, interval: interval}, nil
+}
+
+// NewDNSDiscovery returns a Discovery whose members are resolved from DNS, e.g:
+// the headless services of Kubernetes. The names map the service names to the
+// DNS names with port, e.g: {"RoomService": "room.nano.svc.cluster.local:34580"},
+// every address resolved from the DNS name is a member serving the service.
+// The names will be resolved again every interval if the interval is positive.
+// The members resolved from DNS have no routes, so the AutoDictionary of gate
+// does not contain their routes.
+func NewDNSDiscovery(names map[string]string, interval time.Duration) (Discovery, error) {
+	load := func() ([]*clusterpb.MemberInfo, error) {
+		return resolveMembers(names, net.DefaultResolver.LookupHost)
+	}
+	members, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return &staticDiscovery{members: members, load: load, source: "dns", interval: interval}, nil
+}
+
+// resolveMembers resolves the DNS names and merges the services of the same address,
+// the members are sorted by address
+func resolveMembers(names map[string]string, lookup func(ctx context.Context, host string) ([]string, error)) ([]*clusterpb.MemberInfo, error) {
+	services := map[string][]string{}
+	for service, name := range names {
+		host, port, err := net.SplitHostPort(name)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := lookup(context.Background(), host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			addr = net.JoinHostPort(addr, port)
+			services[addr] = append(services[addr], service)
+		}
+	}
+
+	members := make([]*clusterpb.MemberInfo, 0, len(services))
+	for addr, ss := range services {
+		sort.Strings(ss)
+		members = append(members, &clusterpb.MemberInfo{ServiceAddr: addr, Services: ss})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ServiceAddr < members[j].ServiceAddr
+	})
+	return members, nil
+}
+
+func loadMembers(path string) ([]*clusterpb.MemberInfo, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var members []*clusterpb.MemberInfo
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// Register implements the Discovery interface, the member list is fixed so
+// nothing need to do
+func (d *staticDiscovery) Register(_ *clusterpb.MemberInfo) error {
+	return nil
+}
+
+// Deregister implements the Discovery interface
+func (d *staticDiscovery) Deregister(_ string) error {
+	return nil
+}
+
+// List implements the Discovery interface
+func (d *staticDiscovery) List() ([]*clusterpb.MemberInfo, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	members := make([]*clusterpb.MemberInfo, len(d.members))
+	copy(members, d.members)
+	return members, nil
+}
+
+// Watch implements the Discovery interface
+func (d *staticDiscovery) Watch(fn func(MemberEvent)) func() {
+	if d.load == nil || d.interval <= 0 {
+		return func() {}
+	}
+
+	chStop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				members, err := d.load()
+				if err != nil {
+					log.Println("Reload cluster members failed", d.source, err)
+					continue
+				}
+				for _, event := range d.reload(members) {
+					fn(event)
+				}
+
+			case <-chStop:
+				return
+
+			case <-env.Die:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(chStop) })
+	}
+}
+
+// reload replaces the member list and returns the membership changes
+func (d *staticDiscovery) reload(members []*clusterpb.MemberInfo) []MemberEvent {
+	d.Lock()
+	defer d.Unlock()
+
+	var events []MemberEvent
+	for _, m := range d.members {
+		if findMember(members, m.ServiceAddr) == nil {
+			events = append(events, MemberEvent{Type: MemberRemoved, Member: m})
+		}
+	}
+	for _, m := range members {
+		if old := findMember(d.members, m.ServiceAddr); old == nil || !sameMember(old, m) {
+			events = append(events, MemberEvent{Type: MemberAdded, Member: m})
+		}
+	}
+	d.members = members
+	return events
+}
+
+func findMember(members []*clusterpb.MemberInfo, addr string) *clusterpb.MemberInfo {
+	for _, m := range members {
+		if m.ServiceAddr == addr {
+			return m
+		}
+	}
+	return nil
+}
+
+// sameMember returns whether the member is unchanged, the changed member is
+// announced again so that the services and routes of it are updated
+func sameMember(a, b *clusterpb.MemberInfo) bool {
+	return a.Label == b.Label && a.Draining == b.Draining &&
+		sameStrings(a.Services, b.Services) && sameStrings(a.Routes, b.Routes)
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
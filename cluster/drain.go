@@ -0,0 +1,128 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/revzim/nano/internal/log"
+	"github.com/revzim/nano/session"
+)
+
+// drainCheckInterval is the interval of checking whether the draining finished
+const drainCheckInterval = 10 * time.Millisecond
+
+// DrainKick is the payload of the kick packet sent to clients when the gate
+// is draining, clients should reconnect to another gate
+type DrainKick struct {
+	Reason    string `json:"reason"`
+	Reconnect bool   `json:"reconnect"`
+}
+
+func (n *Node) isDraining() bool {
+	return atomic.LoadInt32(&n.draining) == 1
+}
+
+// Drain drains the current node before shutdown, it returns after all sessions
+// closed or the timeout elapsed. While draining:
+//  1. the gate stops accepting new connections
+//  2. the current node is announced as draining, other members will not bind new
+//     sessions to it, but the sessions bound already are still routed to it
+//  3. the gate kicks all clients with a reconnect hint, and closes the sessions
+//     waiting for resuming
+//  4. waits for the in-flight handlers finished and all sessions closed
+func (n *Node) Drain(timeout time.Duration) {
+	if !atomic.CompareAndSwapInt32(&n.draining, 0, 1) {
+		return
+	}
+	deadline := time.Now().Add(timeout)
+	log.Println("Current node is draining", n.ServiceAddr)
+
+	n.closeListener()
+
+	if n.discovery != nil {
+		if err := n.discovery.Register(n.memberInfo()); err != nil {
+			log.Println("Announce draining to cluster failed", err)
+		}
+	}
+
+	n.expireParked()
+	kick := &DrainKick{Reason: "draining", Reconnect: true}
+	for _, s := range n.agentSessions() {
+		if err := s.NetworkEntity().(*agent).Kick(kick); err != nil {
+			log.Println("Kick session failed", s.ID(), err)
+		}
+	}
+
+	if !n.waitUntil(deadline, func() bool { return atomic.LoadInt64(&n.handler.inflight) == 0 }) {
+		log.Println("Drain timeout with in-flight handlers", atomic.LoadInt64(&n.handler.inflight))
+	}
+
+	if !n.waitUntil(deadline, func() bool { return n.sessionCount() == 0 }) {
+		log.Println("Drain timeout with remaining sessions", n.sessionCount())
+	}
+}
+
+func (n *Node) closeListener() {
+	n.RLock()
+	listener, httpServer := n.listener, n.httpServer
+	n.RUnlock()
+
+	if listener != nil {
+		listener.Close()
+	}
+	if httpServer != nil {
+		httpServer.Close()
+	}
+}
+
+// waitUntil waits until the condition is satisfied or the deadline elapsed, it
+// returns whether the condition is satisfied
+func (n *Node) waitUntil(deadline time.Time, cond func() bool) bool {
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(drainCheckInterval)
+	}
+	return true
+}
+
+func (n *Node) agentSessions() []*session.Session {
+	n.RLock()
+	defer n.RUnlock()
+
+	var sessions []*session.Session
+	for _, s := range n.sessions {
+		if _, ok := s.NetworkEntity().(*agent); ok {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
+
+func (n *Node) sessionCount() int {
+	n.RLock()
+	defer n.RUnlock()
+
+	return len(n.sessions)
+}
@@ -24,7 +24,13 @@ import "errors"
 
 // Errors that could be occurred during message handling.
 var (
-	ErrSessionOnNotify    = errors.New("current session working on notify mode")
-	ErrCloseClosedSession = errors.New("close closed session")
-	ErrInvalidRegisterReq = errors.New("invalid register request")
+	ErrSessionOnNotify     = errors.New("current session working on notify mode")
+	ErrCloseClosedSession  = errors.New("close closed session")
+	ErrInvalidRegisterReq  = errors.New("invalid register request")
+	ErrInactiveMaster      = errors.New("standby master is not active")
+	ErrPushOnCall          = errors.New("cannot push message on cluster call")
+	ErrCallOnScheduler     = errors.New("cannot call on scheduler without the context of handler")
+	ErrInvalidClusterToken = errors.New("invalid cluster token")
+	ErrUIDNotFound         = errors.New("session of uid not found in cluster")
+	ErrDuplicateLogin      = errors.New("uid has been bound to another session")
 )
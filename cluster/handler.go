@@ -24,17 +24,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"io"
 	"net"
 	"reflect"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/revzim/nano/cluster/clusterpb"
 	"github.com/revzim/nano/component"
+	"github.com/revzim/nano/errcode"
 	"github.com/revzim/nano/internal/codec"
 	"github.com/revzim/nano/internal/env"
 	"github.com/revzim/nano/internal/log"
@@ -42,6 +44,7 @@ import (
 	"github.com/revzim/nano/internal/packet"
 	"github.com/revzim/nano/pipeline"
 	"github.com/revzim/nano/scheduler"
+	"github.com/revzim/nano/serialize"
 	"github.com/revzim/nano/session"
 )
 
@@ -51,32 +54,96 @@ const (
 
 var (
 	// cached serialized data
-	hrd []byte // handshake response data
 	hbd []byte // heartbeat packet data
+
+	// cached handshake response, which will be refreshed when the route dictionary changed
+	muHandshake sync.RWMutex
+	hrd         []byte // handshake response data
+	hrdc        []byte // handshake response data without dictionary, the client has cached it
+	dictHash    string // hash of route dictionary
 )
 
-type rpcHandler func(session *session.Session, msg *message.Message, noCopy bool)
+type rpcHandler func(session *session.Session, msg *message.Message, meta requestMeta, noCopy bool)
 
 func cache() {
-	data, err := json.Marshal(map[string]interface{}{
-		"code": 200,
-		"sys":  map[string]float64{"heartbeat": env.Heartbeat.Seconds()},
-	})
+	cacheHandshake()
+
+	var err error
+	hbd, err = codec.Encode(packet.Heartbeat, nil)
 	if err != nil {
 		panic(err)
 	}
+}
+
+// cacheHandshake refreshes the cached handshake response, it should be called
+// after the route dictionary changed
+func cacheHandshake() {
+	muHandshake.Lock()
+	defer muHandshake.Unlock()
+
+	dictHash = message.DictionaryHash()
 
-	hrd, err = codec.Encode(packet.Handshake, data)
+	var err error
+	hrd, err = handshakeResponse(handshakeSys(dictHash, true))
 	if err != nil {
 		panic(err)
 	}
 
-	hbd, err = codec.Encode(packet.Heartbeat, nil)
+	hrdc, err = handshakeResponse(handshakeSys(dictHash, false))
 	if err != nil {
 		panic(err)
 	}
 }
 
+// cachedHandshake returns the cached handshake responses and the dictionary hash
+func cachedHandshake() (full, compact []byte, hash string) {
+	muHandshake.RLock()
+	defer muHandshake.RUnlock()
+
+	return hrd, hrdc, dictHash
+}
+
+// handshakeSys returns the sys section of handshake response, the route dictionary
+// is omitted if the client has cached the dictionary with the same hash. The error
+// responses are always encoded by JSON, which is announced by errorEncoding so that
+// the clients using other serializers can decode them, see errcode.Error.
+func handshakeSys(dictHash string, withDict bool) map[string]interface{} {
+	sys := map[string]interface{}{
+		"heartbeat":     env.Heartbeat.Seconds(),
+		"version":       packet.ProtocolVersion,
+		"serializer":    serialize.Name(env.Serializer),
+		"errorEncoding": "json",
+	}
+	if dictHash != "" {
+		sys["useDict"] = true
+		sys["dictHash"] = dictHash
+		sys["dictVersion"] = dictHash
+		if withDict {
+			sys["dict"] = message.Dictionary()
+		}
+	}
+	return sys
+}
+
+// handshakeResponse encodes the handshake response packet following the pomelo
+// handshake format, e.g:
+// {"code": 200, "sys": {"heartbeat": 30, "dict": {"route": 1}}, "user": {}}
+func handshakeResponse(sys map[string]interface{}) ([]byte, error) {
+	res := map[string]interface{}{
+		"code": 200,
+		"sys":  sys,
+	}
+	if env.HandshakeUserData != nil {
+		res["user"] = env.HandshakeUserData
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(packet.Handshake, data)
+}
+
 type LocalHandler struct {
 	sync.RWMutex
 	localServices map[string]*component.Service // all registered service
@@ -86,6 +153,7 @@ type LocalHandler struct {
 
 	pipeline    pipeline.Pipeline
 	currentNode *Node
+	inflight    int64 // count of handlers scheduled but not finished
 }
 
 func NewHandler(currentNode *Node, pipeline pipeline.Pipeline) *LocalHandler {
@@ -131,6 +199,8 @@ func (h *LocalHandler) addRemoteService(member *clusterpb.MemberInfo) {
 	h.Lock()
 	defer h.Unlock()
 
+	// The services of a member may change when it registers again
+	h.removeMember(member.ServiceAddr)
 	for _, s := range member.Services {
 		log.Println("Register remote service", s)
 		h.remoteServices[s] = append(h.remoteServices[s], member)
@@ -141,12 +211,18 @@ func (h *LocalHandler) delMember(addr string) {
 	h.Lock()
 	defer h.Unlock()
 
+	h.removeMember(addr)
+}
+
+func (h *LocalHandler) removeMember(addr string) {
 	for name, members := range h.remoteServices {
-		for i, maddr := range members {
-			if addr == maddr.ServiceAddr {
-				members = append(members[:i], members[i+1:]...)
+		var remains []*clusterpb.MemberInfo
+		for _, m := range members {
+			if addr != m.ServiceAddr {
+				remains = append(remains, m)
 			}
 		}
+		members = remains
 		if len(members) == 0 {
 			delete(h.remoteServices, name)
 		} else {
@@ -164,6 +240,16 @@ func (h *LocalHandler) LocalService() []string {
 	return result
 }
 
+// LocalRoutes returns the routes of all local handlers
+func (h *LocalHandler) LocalRoutes() []string {
+	var result []string
+	for route := range h.localHandlers {
+		result = append(result, route)
+	}
+	sort.Strings(result)
+	return result
+}
+
 func (h *LocalHandler) RemoteService() []string {
 	h.RLock()
 	defer h.RUnlock()
@@ -177,9 +263,22 @@ func (h *LocalHandler) RemoteService() []string {
 }
 
 func (h *LocalHandler) handle(conn net.Conn) {
+	// admission control before the agent created
+	ip, reason := h.currentNode.admit(conn)
+	if reason != "" {
+		reject(conn, reason)
+		return
+	}
+	defer h.currentNode.leave(ip)
+
 	// create a client agent and startup write gorontine
-	agent := newAgent(conn, h.pipeline, h.remoteProcess)
-	h.currentNode.storeSession(agent.session)
+	agent := newAgent(conn, h.currentNode, h.pipeline, h.remoteProcess)
+	h.currentNode.storeSession(agent.currentSession())
+	s := agent.currentSession()
+	scheduler.PushTask(func() { session.Lifetime.Create(s) })
+
+	agent.limiter = h.currentNode.limiter.acquire(agent)
+	handshakeTimer := h.currentNode.checkHandshake(agent)
 
 	// startup write goroutine
 	go agent.write()
@@ -188,34 +287,20 @@ func (h *LocalHandler) handle(conn net.Conn) {
 		log.Println(fmt.Sprintf("New session established: %s", agent.String()))
 	}
 
-	// guarantee agent related resource be destroyed
+	// guarantee agent related resource be destroyed, the session will be parked
+	// for resuming if the low-level connection was broken
+	var broken bool
 	defer func() {
-		request := &clusterpb.SessionClosedRequest{
-			SessionId: agent.session.ID(),
+		if handshakeTimer != nil {
+			handshakeTimer.Stop()
 		}
-
-		members := h.currentNode.cluster.remoteAddrs()
-		for _, remote := range members {
-			log.Println("Notify remote server success", remote)
-			pool, err := h.currentNode.rpcClient.getConnPool(remote)
-			if err != nil {
-				log.Println("Cannot retrieve connection pool for address", remote, err)
-				continue
-			}
-			client := clusterpb.NewMemberClient(pool.Get())
-			_, err = client.SessionClosed(context.Background(), request)
-			if err != nil {
-				log.Println("Cannot closed session in remote address", remote, err)
-				continue
-			}
-			if env.Debug {
-				log.Println("Notify remote server success", remote)
-			}
+		agent.close()
+		agent.limiter.release()
+		if !broken || !h.currentNode.parkSession(agent) {
+			h.currentNode.closeSession(agent.currentSession(), agent.reason())
 		}
-
-		agent.Close()
 		if env.Debug {
-			log.Println(fmt.Sprintf("Session read goroutine exit, SessionID=%d, UID=%d", agent.session.ID(), agent.session.UID()))
+			log.Println(fmt.Sprintf("Session read goroutine exit, SessionID=%d, UID=%d", agent.currentSession().ID(), agent.currentSession().UID()))
 		}
 	}()
 
@@ -232,6 +317,12 @@ func (h *LocalHandler) handle(conn net.Conn) {
 				return fmt.Sprintf("%s: %s", prependStr, str)
 			}
 			log.Println(errMsg(err.Error()))
+			if err == io.EOF || err.Error() == DefaultWSClientCloseMsg {
+				agent.setCloseReason(session.CloseReasonClient)
+			} else {
+				agent.setCloseReason(session.CloseReasonReadError)
+			}
+			broken = true
 			return
 		}
 
@@ -239,6 +330,7 @@ func (h *LocalHandler) handle(conn net.Conn) {
 		packets, err := agent.decoder.Decode(buf[:n])
 		if err != nil {
 			log.Println(err.Error())
+			agent.setCloseReason(session.CloseReasonDecodeError)
 
 			// process packets decoded
 			for _, p := range packets {
@@ -254,6 +346,7 @@ func (h *LocalHandler) handle(conn net.Conn) {
 		for _, p := range packets {
 			if err := h.processPacket(agent, p); err != nil {
 				log.Println(err.Error())
+				agent.setCloseReason(session.CloseReasonDecodeError)
 				return
 			}
 		}
@@ -267,19 +360,25 @@ func (h *LocalHandler) processPacket(agent *agent, p *packet.Packet) error {
 			return err
 		}
 
-		if _, err := agent.conn.Write(hrd); err != nil {
+		data, err := h.handshake(agent, p.Data)
+		if err != nil {
+			return err
+		}
+		if _, err := agent.conn.Write(data); err != nil {
 			return err
 		}
 
 		agent.setStatus(statusHandshake)
+		s := agent.currentSession()
+		scheduler.PushTask(func() { session.Lifetime.Handshake(s) })
 		if env.Debug {
-			log.Println(fmt.Sprintf("Session handshake Id=%d, Remote=%s", agent.session.ID(), agent.conn.RemoteAddr()))
+			log.Println(fmt.Sprintf("Session handshake Id=%d, Remote=%s", agent.currentSession().ID(), agent.conn.RemoteAddr()))
 		}
 
 	case packet.HandshakeAck:
 		agent.setStatus(statusWorking)
 		if env.Debug {
-			log.Println(fmt.Sprintf("Receive handshake ACK Id=%d, Remote=%s", agent.session.ID(), agent.conn.RemoteAddr()))
+			log.Println(fmt.Sprintf("Receive handshake ACK Id=%d, Remote=%s", agent.currentSession().ID(), agent.conn.RemoteAddr()))
 		}
 
 	case packet.Data:
@@ -292,6 +391,15 @@ func (h *LocalHandler) processPacket(agent *agent, p *packet.Packet) error {
 		if err != nil {
 			return err
 		}
+		if !agent.limiter.allow(msg.Route) {
+			// The dropped request is replied so that the client does not wait for
+			// it, the kicked session is closing
+			if msg.Type == message.Request && !agent.limiter.kicked {
+				err := errcode.New(errcode.TooManyRequests, "rate limited")
+				replyError(agent.currentSession(), msg.ID, msg, err, errcode.TooManyRequests)
+			}
+			break
+		}
 		h.processMessage(agent, msg)
 
 	case packet.Heartbeat:
@@ -308,10 +416,44 @@ func (h *LocalHandler) findMembers(service string) []*clusterpb.MemberInfo {
 	return h.remoteServices[service]
 }
 
-func (h *LocalHandler) remoteProcess(session *session.Session, msg *message.Message, noCopy bool) {
+// selectMember selects a member by the RouteSelector registered to the service,
+// a member will be selected randomly if no RouteSelector registered
+func (h *LocalHandler) selectMember(s *session.Session, service string, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	// The draining members do not accept new sessions unless all members are draining
+	var available []*clusterpb.MemberInfo
+	for _, m := range members {
+		if !m.Draining {
+			available = append(available, m)
+		}
+	}
+	if len(available) > 0 {
+		members = available
+	}
+
+	return h.selector(service).Select(s, service, members)
+}
+
+// selector returns the RouteSelector registered to the service
+func (h *LocalHandler) selector(service string) RouteSelector {
+	if selector, found := h.currentNode.RouteSelectors[service]; found {
+		return selector
+	}
+	return RandomSelector
+}
+
+// bindable returns whether the member selected for the session is bound to router
+func (h *LocalHandler) bindable(s *session.Session, service string) bool {
+	if binder, ok := h.selector(service).(RouteBinder); ok {
+		return binder.Bindable(s)
+	}
+	return true
+}
+
+func (h *LocalHandler) remoteProcess(session *session.Session, msg *message.Message, meta requestMeta, noCopy bool) {
 	index := strings.LastIndex(msg.Route, ".")
 	if index < 0 {
 		log.Println(fmt.Sprintf("nano/handler: invalid route %s", msg.Route))
+		replyError(session, msg.ID, msg, errcode.Newf(errcode.NotFound, "invalid route %s", msg.Route), errcode.NotFound)
 		return
 	}
 
@@ -319,23 +461,22 @@ func (h *LocalHandler) remoteProcess(session *session.Session, msg *message.Mess
 	members := h.findMembers(service)
 	if len(members) == 0 {
 		log.Println(fmt.Sprintf("nano/handler: %s not found(forgot registered?)", msg.Route))
+		replyError(session, msg.ID, msg, errcode.Newf(errcode.NotFound, "route %s not found", msg.Route), errcode.NotFound)
 		return
 	}
 
 	// Select a remote service address
 	// 1. Use the service address directly if the router contains binding item
-	// 2. Select a remote service address randomly and bind to router
+	// 2. Select a remote service address by the RouteSelector and bind to router,
+	//    unless the RouteSelector is a RouteBinder refusing to bind it
 	var remoteAddr string
 	if addr, found := session.Router().Find(service); found {
 		remoteAddr = addr
 	} else {
-		remoteAddr = members[rand.Intn(len(members))].ServiceAddr
-		session.Router().Bind(service, remoteAddr)
-	}
-	pool, err := h.currentNode.rpcClient.getConnPool(remoteAddr)
-	if err != nil {
-		log.Println(err)
-		return
+		remoteAddr = h.selectMember(session, service, members).ServiceAddr
+		if h.bindable(session, service) {
+			session.Router().Bind(service, remoteAddr)
+		}
 	}
 	var data = msg.Data
 	if !noCopy && len(msg.Data) > 0 {
@@ -352,29 +493,99 @@ func (h *LocalHandler) remoteProcess(session *session.Session, msg *message.Mess
 		sessionId = v.sid
 	}
 
+	// The uid and synced keys are carried to the backend session
+	version := bindVersion(session)
+	uid := session.UID()
+	state, err := h.currentNode.syncState(session)
+	if err != nil {
+		log.Println(fmt.Sprintf("Encode session state (%d:%s) error: %+v", msg.ID, msg.Route, err))
+	}
+
+	if h.currentNode.StreamTransport {
+		err = h.streamProcess(remoteAddr, gateAddr, sessionId, uid, version, state, meta, msg, data)
+	} else {
+		err = h.unaryProcess(remoteAddr, gateAddr, sessionId, uid, version, state, meta, msg, data)
+	}
+	if err != nil {
+		log.Println(fmt.Sprintf("Process remote message (%d:%s) error: %+v", msg.ID, msg.Route, err))
+		replyError(session, msg.ID, msg, errcode.Newf(errcode.Unavailable, "route %s unavailable", msg.Route), errcode.Unavailable)
+	}
+}
+
+func (h *LocalHandler) unaryProcess(remoteAddr, gateAddr string, sessionId, uid int64, version uint64, state []byte, meta requestMeta, msg *message.Message, data []byte) error {
+	pool, err := h.currentNode.rpcClient.getConnPool(remoteAddr)
+	if err != nil {
+		return err
+	}
 	client := clusterpb.NewMemberClient(pool.Get())
 	switch msg.Type {
 	case message.Request:
 		request := &clusterpb.RequestMessage{
-			GateAddr:  gateAddr,
-			SessionId: sessionId,
-			Id:        msg.ID,
-			Route:     msg.Route,
-			Data:      data,
+			GateAddr:    gateAddr,
+			SessionId:   sessionId,
+			Id:          msg.ID,
+			Route:       msg.Route,
+			Data:        data,
+			Uid:         uid,
+			State:       state,
+			Timeout:     meta.remoteTimeout(),
+			Metadata:    meta.metadata,
+			BindVersion: version,
 		}
 		_, err = client.HandleRequest(context.Background(), request)
 	case message.Notify:
 		request := &clusterpb.NotifyMessage{
-			GateAddr:  gateAddr,
-			SessionId: sessionId,
-			Route:     msg.Route,
-			Data:      data,
+			GateAddr:    gateAddr,
+			SessionId:   sessionId,
+			Route:       msg.Route,
+			Data:        data,
+			Uid:         uid,
+			State:       state,
+			Timeout:     meta.remoteTimeout(),
+			Metadata:    meta.metadata,
+			BindVersion: version,
 		}
 		_, err = client.HandleNotify(context.Background(), request)
 	}
-	if err != nil {
-		log.Println(fmt.Sprintf("Process remote message (%d:%s) error: %+v", msg.ID, msg.Route, err))
+	return err
+}
+
+func (h *LocalHandler) streamProcess(remoteAddr, gateAddr string, sessionId, uid int64, version uint64, state []byte, meta requestMeta, msg *message.Message, data []byte) error {
+	var streamMsg *clusterpb.StreamMessage
+	switch msg.Type {
+	case message.Request:
+		streamMsg = &clusterpb.StreamMessage{Message: &clusterpb.StreamMessage_Request{
+			Request: &clusterpb.RequestMessage{
+				GateAddr:    gateAddr,
+				SessionId:   sessionId,
+				Id:          msg.ID,
+				Route:       msg.Route,
+				Data:        data,
+				Uid:         uid,
+				State:       state,
+				Timeout:     meta.remoteTimeout(),
+				Metadata:    meta.metadata,
+				BindVersion: version,
+			},
+		}}
+	case message.Notify:
+		streamMsg = &clusterpb.StreamMessage{Message: &clusterpb.StreamMessage_Notify{
+			Notify: &clusterpb.NotifyMessage{
+				GateAddr:    gateAddr,
+				SessionId:   sessionId,
+				Route:       msg.Route,
+				Data:        data,
+				Uid:         uid,
+				State:       state,
+				Timeout:     meta.remoteTimeout(),
+				Metadata:    meta.metadata,
+				BindVersion: version,
+			},
+		}}
+	default:
+		return nil
 	}
+	return h.currentNode.sendStream(remoteAddr, streamMsg)
 }
 
 func (h *LocalHandler) processMessage(agent *agent, msg *message.Message) {
@@ -389,11 +600,12 @@ func (h *LocalHandler) processMessage(agent *agent, msg *message.Message) {
 		return
 	}
 
+	meta := h.currentNode.clientMeta()
 	handler, found := h.localHandlers[msg.Route]
 	if !found {
-		h.remoteProcess(agent.session, msg, false)
+		h.remoteProcess(agent.currentSession(), msg, meta, false)
 	} else {
-		h.localProcess(handler, lastMid, agent.session, msg)
+		h.localProcess(handler, lastMid, agent.currentSession(), msg, meta)
 	}
 }
 
@@ -406,11 +618,12 @@ func (h *LocalHandler) handleWS(conn *websocket.Conn) {
 	go h.handle(c)
 }
 
-func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64, session *session.Session, msg *message.Message) {
+func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64, session *session.Session, msg *message.Message, meta requestMeta) {
 	if pipe := h.pipeline; pipe != nil {
 		err := pipe.Inbound().Process(session, msg)
 		if err != nil {
 			log.Println("Pipeline process failed: " + err.Error())
+			replyError(session, lastMid, msg, err, errcode.Forbidden)
 			return
 		}
 	}
@@ -424,6 +637,7 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 		err := env.Serializer.Unmarshal(payload, data)
 		if err != nil {
 			log.Println(fmt.Sprintf("Deserialize to %T failed: %+v (%v)", data, err, payload))
+			replyError(session, lastMid, msg, err, errcode.BadRequest)
 			return
 		}
 	}
@@ -432,26 +646,68 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 		log.Println(fmt.Sprintf("UID=%d, Message={%s}, Data=%+v", session.UID(), msg.String(), data))
 	}
 
-	args := []reflect.Value{handler.Receiver, reflect.ValueOf(session), reflect.ValueOf(data)}
+	// Whether the task runs on the global scheduler
+	var scheduled bool
 	task := func() {
+		defer atomic.AddInt64(&h.inflight, -1)
+
+		ctx, cancel := handlerContext(session, meta, scheduled)
+		defer cancel()
+		if err := ctx.Err(); err != nil {
+			// The deadline exceeded or the session closed before the handler scheduled
+			log.Println(fmt.Sprintf("Service %s skipped: %v", msg.Route, err))
+			if err == context.DeadlineExceeded {
+				replyError(session, lastMid, msg, errcode.New(errcode.DeadlineExceeded, err.Error()), errcode.DeadlineExceeded)
+			}
+			return
+		}
+
 		switch v := session.NetworkEntity().(type) {
 		case *agent:
 			v.lastMid = lastMid
+			atomic.StoreUint64(&v.answered, 0)
 		case *acceptor:
 			v.lastMid = lastMid
+			atomic.StoreUint64(&v.answered, 0)
 		}
 
-		result := handler.Method.Func.Call(args)
-		if len(result) > 0 {
-			if err := result[0].Interface(); err != nil {
-				log.Println(fmt.Sprintf("Service %s error: %+v", msg.Route, err))
+		// The calls between members are trusted, the session of call has no identity
+		if _, ok := session.NetworkEntity().(*callEntity); !ok {
+			if err := handler.Access.Check(session); err != nil {
+				log.Println(fmt.Sprintf("Service %s rejected: UID=%d, %v", msg.Route, session.UID(), err))
+				replyError(session, lastMid, msg, err, errcode.Forbidden)
+				return
 			}
 		}
+
+		invoker := func(inv *component.Invocation) (interface{}, error) {
+			return invoke(handler, inv)
+		}
+		var interceptors []component.Interceptor
+		interceptors = append(interceptors, h.currentNode.Interceptors...)
+		interceptors = append(interceptors, handler.Interceptors...)
+		if len(interceptors) > 0 {
+			invoker = component.Chain(interceptors, invoker)
+		}
+
+		resp, err := safeInvoke(invoker, &component.Invocation{
+			Route:   msg.Route,
+			Context: ctx,
+			Session: session,
+			Arg:     data,
+		})
+		respond(session, lastMid, msg, resp, err, handler.HasResponse)
+
+		// The call is finished when the handler returned, even if nothing replied
+		if entity, ok := session.NetworkEntity().(*callEntity); ok {
+			entity.done(&clusterpb.CallResponse{})
+		}
 	}
 
 	index := strings.LastIndex(msg.Route, ".")
 	if index < 0 {
 		log.Println(fmt.Sprintf("nano/handler: invalid route %s", msg.Route))
+		replyError(session, lastMid, msg, errcode.Newf(errcode.NotFound, "invalid route %s", msg.Route), errcode.NotFound)
 		return
 	}
 
@@ -460,18 +716,28 @@ func (h *LocalHandler) localProcess(handler *component.Handler, lastMid uint64,
 	if s, found := h.localServices[service]; found && s.SchedName != "" {
 		sched := session.Value(s.SchedName)
 		if sched == nil {
-			log.Println(fmt.Sprintf("nanl/handler: cannot found `schedular.LocalScheduler` by %s", s.SchedName))
+			err := fmt.Errorf("nanl/handler: cannot found `schedular.LocalScheduler` by %s", s.SchedName)
+			log.Println(err.Error())
+			replyError(session, lastMid, msg, err, errcode.Internal)
 			return
 		}
 
 		local, ok := sched.(scheduler.LocalScheduler)
 		if !ok {
-			log.Println(fmt.Sprintf("nanl/handler: Type %T does not implement the `schedular.LocalScheduler` interface",
-				sched))
+			err := fmt.Errorf("nanl/handler: Type %T does not implement the `schedular.LocalScheduler` interface", sched)
+			log.Println(err.Error())
+			replyError(session, lastMid, msg, err, errcode.Internal)
 			return
 		}
+		atomic.AddInt64(&h.inflight, 1)
 		local.Schedule(task)
 	} else {
-		scheduler.PushTask(task)
+		scheduled = true
+		atomic.AddInt64(&h.inflight, 1)
+		// The handlers called back by the calls which the scheduler is waiting
+		// for are run by the waiting scheduler, see Node.Call
+		if !waitingCalls.schedule(meta.chain, task) {
+			scheduler.PushTask(task)
+		}
 	}
 }
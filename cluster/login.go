@@ -0,0 +1,286 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/internal/log"
+	"github.com/revzim/nano/scheduler"
+	"github.com/revzim/nano/session"
+)
+
+// DuplicateLoginPolicy decides what to do when a uid is bound to a session while
+// another session has been bound to the same uid in cluster
+type DuplicateLoginPolicy int
+
+const (
+	// AllowMultiple allows a uid bound to multiple sessions, the directory maps the
+	// uid to the latest session, and to the earlier one after the latest closed
+	AllowMultiple DuplicateLoginPolicy = iota
+	// KickOld kicks the session bound to the uid before, the session closed callbacks
+	// of the old session will be called after the replaced callbacks
+	KickOld
+	// RejectNew rejects the new binding with ErrDuplicateLogin. The bindings unknown
+	// to current node are claimed asynchronously, and the new session is unbound and
+	// kicked if the binding is rejected by the owner of uid, see Node.claimSession
+	RejectNew
+)
+
+// DuplicateLoginKick is the payload of the kick packet sent to the client whose
+// session was replaced by a new login of the same uid
+type DuplicateLoginKick struct {
+	Reason string `json:"reason"`
+}
+
+// uidLocks serializes the claims of the same uid
+type uidLocks struct {
+	sync.Mutex
+	locks map[int64]*uidLock
+}
+
+type uidLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the uid and returns the function to unlock it
+func (l *uidLocks) lock(uid int64) func() {
+	l.Lock()
+	if l.locks == nil {
+		l.locks = map[int64]*uidLock{}
+	}
+	ul, found := l.locks[uid]
+	if !found {
+		ul = &uidLock{}
+		l.locks[uid] = ul
+	}
+	ul.refs++
+	l.Unlock()
+
+	ul.Lock()
+	return func() {
+		ul.Unlock()
+		l.Lock()
+		ul.refs--
+		if ul.refs == 0 {
+			delete(l.locks, uid)
+		}
+		l.Unlock()
+	}
+}
+
+// claimSession asks the owner of uid to bind the uid to the session which lives in
+// the location, and applies the DuplicateLoginPolicy with the binding found by the
+// owner. The bindings of a uid are decided by its owner, so that the concurrent
+// logins of the same uid in cluster are checked one by one. The binding is
+// announced to all members after claimed, and the session is unbound and kicked
+// if the owner rejected it. The claim is cancelled if the session unbound or closed
+// while claiming.
+//
+// The owner and members are called with the deadline of directoryTimeout, so
+// claimSession should not be called in the scheduler, see bindSession.
+func (n *Node) claimSession(s *session.Session, uid int64, loc sessionLocation) {
+	ctx, cancel := context.WithTimeout(context.Background(), directoryTimeout)
+	defer cancel()
+
+	var (
+		prev  sessionLocation
+		found bool
+		err   error
+	)
+	if owner := n.uidOwner(uid); owner == n.ServiceAddr {
+		prev, found, err = n.claim(ctx, uid, loc, n.DuplicateLogin)
+	} else {
+		prev, found, err = n.claimRemote(ctx, owner, uid, loc)
+	}
+	if err != nil {
+		if n.directory.cancelClaim(uid, loc) {
+			log.Println("Claim session rejected", uid, loc.gateAddr, loc.sid, err)
+			scheduler.PushTask(func() { n.rejectSession(s, uid, loc) })
+		}
+		return
+	}
+
+	// The owner of uid has updated its directory only
+	switch current := n.directory.confirm(uid, loc); current {
+	case uid:
+	case 0:
+		// The binding claimed in the owner is removed by the unbinding
+		n.unbindSession(uid, loc.gateAddr, loc.sid)
+		return
+	default:
+		// The binding claimed is replaced by the binding of current uid
+		return
+	}
+	n.announceBinding(&clusterpb.SessionBoundRequest{
+		GateAddr:  loc.gateAddr,
+		SessionId: loc.sid,
+		Uid:       uid,
+	})
+	if !found || n.DuplicateLogin != KickOld {
+		return
+	}
+
+	if old := n.replacedSession(uid, prev); old != nil {
+		scheduler.PushTask(func() { session.Lifetime.Replace(old, s) })
+	}
+	if err := n.kickSession(prev, &DuplicateLoginKick{Reason: "duplicate login"}); err != nil {
+		log.Println("Kick the session of duplicate login failed", uid, err)
+	}
+}
+
+// rejectSession unbinds the uid from the session rejected by the owner of uid and
+// kicks the session, it should be called in the scheduler
+func (n *Node) rejectSession(s *session.Session, uid int64, loc sessionLocation) {
+	if s.UID() == uid {
+		if err := s.Unbind(); err != nil {
+			log.Println("Unbind the rejected session failed", uid, err)
+		}
+	}
+	go func() {
+		if err := n.kickSession(loc, &DuplicateLoginKick{Reason: "duplicate login"}); err != nil {
+			log.Println("Kick the rejected session failed", uid, err)
+		}
+	}()
+}
+
+// uidOwner returns the member which decides the bindings of the uid. The master
+// decides all bindings if the cluster is maintained by master, so that the owner
+// is not changed while members join and leave. Otherwise the owner is chosen from
+// the members sorted by address, and the members may choose different owners for
+// a moment while the membership changes, the bindings missed by the new owner are
+// found by asking all members, see Node.claim.
+func (n *Node) uidOwner(uid int64) string {
+	if d, ok := n.discovery.(*masterDiscovery); ok {
+		if master := d.currentMaster(); master != "" {
+			return master
+		}
+		if n.cluster.isActive() {
+			return n.ServiceAddr
+		}
+	}
+
+	addrs := []string{n.ServiceAddr}
+	for _, addr := range n.cluster.remoteAddrs() {
+		if addr != n.ServiceAddr {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Strings(addrs)
+	return addrs[uint64(uid)%uint64(len(addrs))]
+}
+
+// claim binds the uid to the location in the directory of current node, the members
+// are asked if the uid is not found in the directory, e.g: the uid was bound before
+// current node joined the cluster
+func (n *Node) claim(ctx context.Context, uid int64, loc sessionLocation, policy DuplicateLoginPolicy) (sessionLocation, bool, error) {
+	unlock := n.claims.lock(uid)
+	defer unlock()
+
+	if _, found := n.directory.find(uid); !found {
+		if prev, found := n.findRemote(ctx, uid); found && prev != loc {
+			n.directory.set(uid, prev)
+		}
+	}
+	return n.directory.claim(uid, loc, policy)
+}
+
+// claimRemote asks the owner of uid to bind the uid to the location, the uid will
+// be claimed by current node if the owner is unreachable
+func (n *Node) claimRemote(ctx context.Context, owner string, uid int64, loc sessionLocation) (sessionLocation, bool, error) {
+	request := &clusterpb.ClaimSessionRequest{
+		Uid:       uid,
+		GateAddr:  loc.gateAddr,
+		SessionId: loc.sid,
+		Policy:    int32(n.DuplicateLogin),
+	}
+	pool, err := n.rpcClient.getConnPool(owner)
+	if err != nil {
+		log.Println("Cannot retrieve connection pool for address", owner, err)
+		return n.claim(ctx, uid, loc, n.DuplicateLogin)
+	}
+	resp, err := clusterpb.NewMemberClient(pool.Get()).ClaimSession(ctx, request)
+	if err != nil {
+		log.Println("Claim session in remote address failed", owner, err)
+		return n.claim(ctx, uid, loc, n.DuplicateLogin)
+	}
+
+	prev := sessionLocation{gateAddr: resp.GateAddr, sid: resp.SessionId}
+	if !resp.Claimed {
+		return prev, resp.Found, ErrDuplicateLogin
+	}
+	return prev, resp.Found, nil
+}
+
+// replacedSession returns the session replaced by the new login of the uid, the
+// replaced session is created as a remote session if it has never been routed to
+// current node
+func (n *Node) replacedSession(uid int64, loc sessionLocation) *session.Session {
+	if s := n.findSessionAt(loc); s != nil {
+		return s
+	}
+	if loc.gateAddr == n.ServiceAddr {
+		return nil
+	}
+	s, err := n.findOrCreateSession(loc.sid, loc.gateAddr)
+	if err != nil {
+		log.Println("Create the replaced session failed", uid, err)
+		return nil
+	}
+	s.Synchronize(uid, nil)
+	return s
+}
+
+// ClaimSession implements the MemberServer interface
+func (n *Node) ClaimSession(ctx context.Context, req *clusterpb.ClaimSessionRequest) (*clusterpb.ClaimSessionResponse, error) {
+	loc := sessionLocation{gateAddr: req.GateAddr, sid: req.SessionId}
+	prev, found, err := n.claim(ctx, req.Uid, loc, DuplicateLoginPolicy(req.Policy))
+	return &clusterpb.ClaimSessionResponse{
+		Claimed:   err == nil,
+		Found:     found,
+		GateAddr:  prev.gateAddr,
+		SessionId: prev.sid,
+	}, nil
+}
+
+// findSessionAt returns the session which lives in the location, it returns nil if
+// the session has never been routed to current node
+func (n *Node) findSessionAt(loc sessionLocation) *session.Session {
+	s := n.findSession(loc.sid)
+	if s == nil {
+		return nil
+	}
+	switch entity := s.NetworkEntity().(type) {
+	case *agent:
+		if loc.gateAddr == n.ServiceAddr {
+			return s
+		}
+	case *acceptor:
+		if loc.gateAddr == entity.gateAddr {
+			return s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/internal/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// masterDiscovery is the default Discovery, which maintains the membership by a master
+// node. Members register to the master and renew their lease by heartbeat, and the
+// master pushes membership changes to all members through the Member service.
+type masterDiscovery struct {
+	sync.RWMutex
+	node       *Node
+	masterAddr string                  // address of the master which current node registered to
+	members    []*clusterpb.MemberInfo // members in cluster when current node registered
+	fn         func(MemberEvent)
+}
+
+func newMasterDiscovery(node *Node) *masterDiscovery {
+	return &masterDiscovery{node: node}
+}
+
+// Register implements the Discovery interface
+func (d *masterDiscovery) Register(member *clusterpb.MemberInfo) error {
+	n := d.node
+	// Update the member information of registered node, e.g: draining
+	if n.cluster.isActive() {
+		n.cluster.updateMaster(member)
+		return nil
+	}
+	if master := d.currentMaster(); master != "" {
+		_, err := d.register(master, member)
+		return err
+	}
+
+	if n.IsMaster {
+		n.cluster.activate(member)
+		if n.MemberHeartbeat > 0 {
+			go n.cluster.checkHeartbeat(n.MemberHeartbeat, n.MaxMissedHeartbeats)
+		}
+		return nil
+	}
+
+	for {
+		_, err := d.register(n.AdvertiseAddr, member)
+		if err == nil {
+			break
+		}
+		log.Println("Register current node to cluster failed", err, "and will retry in", n.RetryInterval.String())
+		time.Sleep(n.RetryInterval)
+	}
+	if n.MemberHeartbeat > 0 {
+		go d.heartbeat(member)
+	}
+	return nil
+}
+
+// Deregister implements the Discovery interface
+func (d *masterDiscovery) Deregister(addr string) error {
+	n := d.node
+	if n.IsMaster || n.cluster.isActive() {
+		return nil
+	}
+
+	pool, err := n.rpcClient.getConnPool(d.currentMaster())
+	if err != nil {
+		return err
+	}
+	client := clusterpb.NewMasterClient(pool.Get())
+	request := &clusterpb.UnregisterRequest{
+		ServiceAddr: addr,
+	}
+	_, err = client.Unregister(context.Background(), request)
+	return err
+}
+
+// List implements the Discovery interface
+func (d *masterDiscovery) List() ([]*clusterpb.MemberInfo, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	return d.members, nil
+}
+
+// Watch implements the Discovery interface, the membership changes are pushed by
+// master through the Member service, so only the changes found by current node
+// will be delivered to the function
+func (d *masterDiscovery) Watch(fn func(MemberEvent)) func() {
+	d.Lock()
+	defer d.Unlock()
+
+	d.fn = fn
+	return func() {
+		d.Lock()
+		defer d.Unlock()
+		d.fn = nil
+	}
+}
+
+func (d *masterDiscovery) notify(event MemberEvent) {
+	d.RLock()
+	fn := d.fn
+	d.RUnlock()
+
+	if fn != nil {
+		fn(event)
+	}
+}
+
+func (d *masterDiscovery) currentMaster() string {
+	d.RLock()
+	defer d.RUnlock()
+
+	return d.masterAddr
+}
+
+// register registers the member to the master and returns the members in cluster
+func (d *masterDiscovery) register(masterAddr string, member *clusterpb.MemberInfo) ([]*clusterpb.MemberInfo, error) {
+	pool, err := d.node.rpcClient.getConnPool(masterAddr)
+	if err != nil {
+		return nil, err
+	}
+	client := clusterpb.NewMasterClient(pool.Get())
+	request := &clusterpb.RegisterRequest{MemberInfo: member}
+	resp, err := client.Register(context.Background(), request)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Lock()
+	d.masterAddr = masterAddr
+	d.members = resp.Members
+	d.Unlock()
+	return resp.Members, nil
+}
+
+// reregister registers the member to the master again, the members which were known
+// before but are absent in the master may have been down while the master was
+// unavailable, they will be removed if they don't register again in time
+func (d *masterDiscovery) reregister(masterAddr string, member *clusterpb.MemberInfo) error {
+	n := d.node
+	registered, err := d.register(masterAddr, member)
+	if err != nil {
+		return err
+	}
+	for _, m := range registered {
+		d.notify(MemberEvent{Type: MemberAdded, Member: m})
+	}
+
+	var members []*clusterpb.MemberInfo
+	for _, m := range n.cluster.absentMembers(registered) {
+		members = append(members, m.memberInfo)
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	time.AfterFunc(n.MemberHeartbeat*time.Duration(n.MaxMissedHeartbeats), func() {
+		for _, info := range members {
+			// The member information will be replaced when it registers again
+			if n.cluster.memberInfo(info.ServiceAddr) != info {
+				continue
+			}
+			log.Println("Member does not register again and will be removed", info.ServiceAddr)
+			d.notify(MemberEvent{Type: MemberRemoved, Member: info})
+		}
+	})
+	return nil
+}
+
+// heartbeat renews the lease of current node in master until the current node shutdown,
+// the current node will register again if the master lost the registration of it, and
+// fail over to the standby masters if the master is unreachable
+func (d *masterDiscovery) heartbeat(member *clusterpb.MemberInfo) {
+	n := d.node
+	ticker := time.NewTicker(n.MemberHeartbeat)
+	defer ticker.Stop()
+
+	var missed int
+	request := &clusterpb.HeartbeatRequest{ServiceAddr: member.ServiceAddr}
+	for {
+		select {
+		case <-ticker.C:
+			master := d.currentMaster()
+			pool, err := n.rpcClient.getConnPool(master)
+			if err != nil {
+				log.Println("Retrieve master address error", err)
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), n.MemberHeartbeat)
+			_, err = clusterpb.NewMasterClient(pool.Get()).Heartbeat(ctx, request)
+			cancel()
+
+			switch {
+			case err == nil:
+				missed = 0
+
+			case status.Code(err) == codes.NotFound:
+				log.Println("Current node is unknown to master and will register again", master)
+				if err := d.reregister(master, n.memberInfo()); err != nil {
+					log.Println("Register current node to cluster failed", err)
+				}
+
+			default:
+				missed++
+				log.Println("Send heartbeat to master failed", master, err)
+				if missed >= n.MaxMissedHeartbeats && d.failover(master, n.memberInfo()) {
+					return
+				}
+			}
+
+		case <-n.chDie:
+			return
+		}
+	}
+}
+
+// failover registers the member to the next available master, a standby master
+// node will take over the master role if no other master available. It returns
+// true if the current node has become master
+func (d *masterDiscovery) failover(current string, member *clusterpb.MemberInfo) bool {
+	n := d.node
+	for _, addr := range append([]string{n.AdvertiseAddr}, n.StandbyAddrs...) {
+		if addr == current || addr == member.ServiceAddr {
+			continue
+		}
+		if err := d.reregister(addr, member); err != nil {
+			log.Println("Fail over to master failed", addr, err)
+			continue
+		}
+		log.Println("Current node fails over to master", addr)
+		return false
+	}
+
+	if !n.IsStandby {
+		return false
+	}
+
+	log.Println("Master is unreachable and standby master takes over the master role", member.ServiceAddr)
+	d.Lock()
+	d.masterAddr = member.ServiceAddr
+	d.Unlock()
+	n.cluster.activate(member)
+	go n.cluster.checkHeartbeat(n.MemberHeartbeat, n.MaxMissedHeartbeats)
+	return true
+}
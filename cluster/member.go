@@ -20,11 +20,16 @@
 
 package cluster
 
-import "github.com/revzim/nano/cluster/clusterpb"
+import (
+	"time"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+)
 
 type Member struct {
-	isMaster   bool
-	memberInfo *clusterpb.MemberInfo
+	isMaster        bool
+	memberInfo      *clusterpb.MemberInfo
+	lastHeartbeatAt time.Time // last heartbeat time, only maintained by master
 }
 
 func (m *Member) MemberInfo() *clusterpb.MemberInfo {
@@ -0,0 +1,101 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/internal/log"
+	"github.com/revzim/nano/internal/message"
+	"github.com/revzim/nano/session"
+)
+
+// Multicast pushes the message to the sessions, the sessions which live in the
+// same remote gate are pushed by one batched RPC and the gate fans out the message
+// locally, other sessions are pushed one by one. It returns the last push error.
+func Multicast(sessions []*session.Session, route string, v interface{}) error {
+	data, err := message.Serialize(v)
+	if err != nil {
+		return err
+	}
+
+	type gate struct {
+		node *Node
+		addr string
+	}
+	batches := map[gate][]int64{}
+	var lastErr error
+	for _, s := range sessions {
+		if a, ok := s.NetworkEntity().(*acceptor); ok {
+			g := gate{node: a.node, addr: a.gateAddr}
+			batches[g] = append(batches[g], a.sid)
+			continue
+		}
+		if err := s.Push(route, data); err != nil {
+			log.Println(fmt.Sprintf("Session push message error, ID=%d, UID=%d, Error=%s", s.ID(), s.UID(), err.Error()))
+			lastErr = err
+		}
+	}
+
+	for g, sids := range batches {
+		request := &clusterpb.MulticastMessage{
+			SessionIds: sids,
+			Route:      route,
+			Data:       data,
+		}
+		if err := g.node.multicast(g.addr, request); err != nil {
+			log.Println(fmt.Sprintf("Multicast message to gate error, Gate=%s, Sessions=%d, Error=%s", g.addr, len(sids), err.Error()))
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (n *Node) multicast(gateAddr string, request *clusterpb.MulticastMessage) error {
+	if n.StreamTransport {
+		return n.sendStream(gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Multicast{Multicast: request},
+		})
+	}
+	pool, err := n.rpcClient.getConnPool(gateAddr)
+	if err != nil {
+		return err
+	}
+	_, err = clusterpb.NewMemberClient(pool.Get()).HandleMulticast(context.Background(), request)
+	return err
+}
+
+// HandleMulticast implements the MemberServer interface
+func (n *Node) HandleMulticast(_ context.Context, req *clusterpb.MulticastMessage) (*clusterpb.MemberHandleResponse, error) {
+	for _, sid := range req.SessionIds {
+		s := n.findSession(sid)
+		if s == nil {
+			log.Println(fmt.Sprintf("Multicast to session not found, ID=%d", sid))
+			continue
+		}
+		if err := s.Push(req.Route, req.Data); err != nil {
+			log.Println(fmt.Sprintf("Session push message error, ID=%d, UID=%d, Error=%s", s.ID(), s.UID(), err.Error()))
+		}
+	}
+	return &clusterpb.MemberHandleResponse{}, nil
+}
@@ -22,6 +22,8 @@ package cluster
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -33,6 +35,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/revzim/nano/cluster/clusterpb"
 	"github.com/revzim/nano/component"
+	"github.com/revzim/nano/errcode"
 	"github.com/revzim/nano/internal/env"
 	"github.com/revzim/nano/internal/log"
 	"github.com/revzim/nano/internal/message"
@@ -54,6 +57,91 @@ type Options struct {
 	IsWebsocket    bool
 	TSLCertificate string
 	TSLKey         string
+
+	// IsStandby indicates the current node is a standby master, which registers to
+	// the master at AdvertiseAddr as a member and takes over the master role when
+	// the master is unreachable. Only one standby master is supported in a cluster.
+	IsStandby bool
+	// StandbyAddrs are the addresses of standby masters, members will fail over to
+	// them in order when the master at AdvertiseAddr is unreachable
+	StandbyAddrs []string
+
+	// Discovery maintains the cluster membership, the master node will be used
+	// to maintain the membership if it is not set
+	Discovery Discovery
+
+	// RouteSelectors are the RouteSelector of services, which select the member to
+	// handle the messages of the service, the service that has no RouteSelector
+	// selects member randomly
+	RouteSelectors map[string]RouteSelector
+
+	// StreamTransport indicates the messages between members are transferred by
+	// long-lived bidirectional streams instead of unary calls
+	StreamTransport bool
+
+	// ClusterTLS is used to secure the gRPC traffic between members, the certificate
+	// of peer will be verified, see NewClusterTLSConfig
+	ClusterTLS *tls.Config
+	// ClusterToken is a pre-shared token, the gRPC calls from the member which does
+	// not carry the same token will be rejected
+	ClusterToken string
+
+	// DrainTimeout is the max duration of draining before shutdown, see Node.Drain,
+	// the node is shutdown without draining if not positive
+	DrainTimeout time.Duration
+
+	// SyncKeys are the keys of session data which will be synchronized from gate
+	// to the backend sessions with the forwarded messages, the backends can write
+	// them back by session.Session.Sync. The types of values except the builtin
+	// types should be registered by gob.Register.
+	SyncKeys []string
+
+	// SessionStore persists the session keys opted in by session.Session.Persist,
+	// session.DefaultStore is used if it is nil
+	SessionStore session.Store
+
+	// DuplicateLogin is the policy applied when a uid is bound to a session while
+	// another session has been bound to the same uid in cluster
+	DuplicateLogin DuplicateLoginPolicy
+
+	// HandshakeTimeout is the deadline of clients finishing handshake after
+	// connected, it is disabled if not positive
+	HandshakeTimeout time.Duration
+	// MaxSessions is the max count of concurrent sessions of gate, and the
+	// MaxSessionsPerIP is the max count of the sessions from the same remote
+	// IP. The connection exceeding the caps will be kicked with AdmissionKick.
+	// They are unlimited if not positive.
+	MaxSessions      int
+	MaxSessionsPerIP int
+
+	// Interceptors are the global interceptors of all handlers, which are called
+	// before the interceptors of the component and the method
+	Interceptors []component.Interceptor
+
+	// RequestTimeout is the deadline of the messages from clients, which is
+	// propagated to the members handling the messages, and the handlers can
+	// retrieve it from the context.Context argument. No deadline if not positive.
+	RequestTimeout time.Duration
+
+	// RateLimit limits the messages sent by clients, the messages are not limited
+	// if it is nil
+	RateLimit *RateLimitOptions
+
+	// AutoDictionary generates the route dictionary from the routes of local
+	// handlers and the handlers of cluster members, see message.AddRoutes. The
+	// dictionary is sent to clients in the handshake response.
+	AutoDictionary bool
+
+	// SessionResume is the grace period of keeping the session whose connection was
+	// broken, the client can resume the session by the resume token issued in the
+	// handshake response. Session resuming is disabled if it is not positive.
+	SessionResume time.Duration
+
+	// MemberHeartbeat is the interval of members renewing their lease in master,
+	// the member will be evicted after MaxMissedHeartbeats heartbeats missed.
+	// Heartbeat is disabled if the interval is not positive.
+	MemberHeartbeat     time.Duration
+	MaxMissedHeartbeats int
 }
 
 // Node represents a node in nano cluster, which will contains a group of services.
@@ -69,7 +157,23 @@ type Node struct {
 	server    *grpc.Server
 	rpcClient *rpcClient
 
-	sessions map[int64]*session.Session
+	discovery     Discovery
+	unwatch       func() // stops watching the membership changes
+	sessions      map[int64]*session.Session
+	parked        map[string]*parkedSession // resume token to parked session
+	streams       map[string]*memberStream  // member address to stream opened by current node
+	inbound       map[string]*inboundStream // member address to stream opened by the member
+	directory     *directory
+	claims        uidLocks       // serializes the claims of the uids owned by current node
+	announcements *announcements // binding announcements to send
+	limiter       *rateLimiter
+	admission     *admission
+	chDie         chan struct{}
+	stopOnce      sync.Once
+
+	listener   net.Listener // client listener of tcp mode
+	httpServer *http.Server // client server of websocket mode
+	draining   int32
 
 	// mongoDriver    *drivers.AZMongoApp
 	// firebaseDriver *drivers.AZFirebaseApp
@@ -79,7 +183,18 @@ func (n *Node) Startup() error {
 	if n.ServiceAddr == "" {
 		return errors.New("service address cannot be empty in master node")
 	}
+	if n.MaxMissedHeartbeats < 1 {
+		n.MaxMissedHeartbeats = defaultMaxMissedHeartbeats
+	}
 	n.sessions = map[int64]*session.Session{}
+	n.parked = map[string]*parkedSession{}
+	n.streams = map[string]*memberStream{}
+	n.inbound = map[string]*inboundStream{}
+	n.directory = newDirectory()
+	n.announcements = newAnnouncements()
+	n.limiter = newRateLimiter(n.RateLimit)
+	n.admission = newAdmission()
+	n.chDie = make(chan struct{})
 	n.cluster = newCluster(n)
 	n.handler = NewHandler(n, n.Pipeline)
 	components := n.Components.List()
@@ -90,10 +205,18 @@ func (n *Node) Startup() error {
 		}
 	}
 
+	if n.AutoDictionary {
+		if err := n.addRoutes(n.handler.LocalRoutes()); err != nil {
+			return err
+		}
+	}
+
 	cache()
+	scheduler.PushTask(markScheduler)
 	if err := n.initNode(); err != nil {
 		return err
 	}
+	go n.announce()
 
 	// Initialize all components
 	for _, c := range components {
@@ -125,10 +248,14 @@ func (n *Node) Handler() *LocalHandler {
 }
 
 func (n *Node) initNode() error {
-	// Current node is not master server and does not contains master
-	// address, so running in singleton mode
-	if !n.IsMaster && n.AdvertiseAddr == "" {
-		return nil
+	n.discovery = n.Discovery
+	if n.discovery == nil {
+		// Current node is not master server and does not contains master
+		// address, so running in singleton mode
+		if !n.IsMaster && n.AdvertiseAddr == "" {
+			return nil
+		}
+		n.discovery = newMasterDiscovery(n)
 	}
 
 	listener, err := net.Listen("tcp", n.ServiceAddr)
@@ -137,61 +264,78 @@ func (n *Node) initNode() error {
 	}
 
 	// Initialize the gRPC server and register service
-	n.server = grpc.NewServer()
-	n.rpcClient = newRPCClient()
+	n.server = grpc.NewServer(n.serverOptions()...)
+	n.rpcClient = newRPCClient(n.dialOptions()...)
+	n.cluster.setRpcClient(n.rpcClient)
 	clusterpb.RegisterMemberServer(n.server, n)
+	// Standby master serves the master service after taking over the master role
+	if n.Discovery == nil && (n.IsMaster || n.IsStandby) {
+		clusterpb.RegisterMasterServer(n.server, n.cluster)
+	}
 
 	go func() {
 		err := n.server.Serve(listener)
-		if err != nil {
+		if err != nil && err != grpc.ErrServerStopped {
 			log.Fatalf("Start current node failed: %v", err)
 		}
 	}()
 
-	if n.IsMaster {
-		clusterpb.RegisterMasterServer(n.server, n.cluster)
-		member := &Member{
-			isMaster: true,
-			memberInfo: &clusterpb.MemberInfo{
-				Label:       n.Label,
-				ServiceAddr: n.ServiceAddr,
-				Services:    n.handler.LocalService(),
-			},
-		}
-		n.cluster.members = append(n.cluster.members, member)
-		n.cluster.setRpcClient(n.rpcClient)
-	} else {
-		pool, err := n.rpcClient.getConnPool(n.AdvertiseAddr)
-		if err != nil {
-			return err
-		}
-		client := clusterpb.NewMasterClient(pool.Get())
-		request := &clusterpb.RegisterRequest{
-			MemberInfo: &clusterpb.MemberInfo{
-				Label:       n.Label,
-				ServiceAddr: n.ServiceAddr,
-				Services:    n.handler.LocalService(),
-			},
-		}
-		for {
-			resp, err := client.Register(context.Background(), request)
-			if err == nil {
-				n.handler.initRemoteService(resp.Members)
-				n.cluster.initMembers(resp.Members)
-				break
-			}
-			log.Println("Register current node to cluster failed", err, "and will retry in", n.RetryInterval.String())
-			time.Sleep(n.RetryInterval)
-		}
-
+	n.unwatch = n.discovery.Watch(n.onMemberEvent)
+	if err := n.discovery.Register(n.memberInfo()); err != nil {
+		return err
+	}
+	members, err := n.discovery.List()
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		n.onMemberEvent(MemberEvent{Type: MemberAdded, Member: m})
 	}
 
 	return nil
 }
 
-// Shutdowns all components registered by application, that
-// call by reverse order against register
+func (n *Node) memberInfo() *clusterpb.MemberInfo {
+	return &clusterpb.MemberInfo{
+		Label:       n.Label,
+		ServiceAddr: n.ServiceAddr,
+		Services:    n.handler.LocalService(),
+		Draining:    n.isDraining(),
+		Routes:      n.handler.LocalRoutes(),
+	}
+}
+
+// onMemberEvent updates the remote services when cluster membership changes
+func (n *Node) onMemberEvent(event MemberEvent) {
+	if event.Member == nil || event.Member.ServiceAddr == n.ServiceAddr {
+		return
+	}
+
+	switch event.Type {
+	case MemberAdded:
+		n.handler.addRemoteService(event.Member)
+		n.cluster.addMember(event.Member)
+		if n.AutoDictionary {
+			if err := n.addRoutes(event.Member.Routes); err != nil {
+				log.Println(fmt.Sprintf("Add routes of member %s failed: %v", event.Member.ServiceAddr, err))
+			}
+		}
+	case MemberRemoved:
+		n.directory.removeGate(event.Member.ServiceAddr)
+		n.handler.delMember(event.Member.ServiceAddr)
+		n.cluster.delMember(event.Member.ServiceAddr)
+		n.rebindSessions(event.Member.ServiceAddr)
+	}
+}
+
+// Shutdown stops the node, it is safe to call Shutdown more than once
 func (n *Node) Shutdown() {
+	n.stopOnce.Do(n.shutdown)
+}
+
+func (n *Node) shutdown() {
+	close(n.chDie)
+
 	// reverse call `BeforeShutdown` hooks
 	components := n.Components.List()
 	length := len(components)
@@ -204,24 +348,16 @@ func (n *Node) Shutdown() {
 		components[i].Comp.Shutdown()
 	}
 
-	if !n.IsMaster && n.AdvertiseAddr != "" {
-		pool, err := n.rpcClient.getConnPool(n.AdvertiseAddr)
-		if err != nil {
-			log.Println("Retrieve master address error", err)
-			goto EXIT
-		}
-		client := clusterpb.NewMasterClient(pool.Get())
-		request := &clusterpb.UnregisterRequest{
-			ServiceAddr: n.ServiceAddr,
-		}
-		_, err = client.Unregister(context.Background(), request)
-		if err != nil {
+	if n.unwatch != nil {
+		n.unwatch()
+	}
+	if n.discovery != nil {
+		if err := n.discovery.Deregister(n.ServiceAddr); err != nil {
 			log.Println("Unregister current node failed", err)
-			goto EXIT
 		}
 	}
 
-EXIT:
+	n.closeStreams()
 	if n.server != nil {
 		n.server.GracefulStop()
 	}
@@ -233,11 +369,18 @@ func (n *Node) listenAndServe() {
 	if err != nil {
 		log.Fatal(err.Error())
 	}
+	n.Lock()
+	n.listener = listener
+	n.Unlock()
 
 	defer listener.Close()
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
+			// The listener was closed by drain
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
 			log.Println(err.Error())
 			continue
 		}
@@ -288,7 +431,7 @@ func (n *Node) listenAndServeWS() {
 		n.handler.handleWS(conn)
 	})
 
-	if err := http.ListenAndServe(n.ClientAddr, nil); err != nil {
+	if err := n.newHTTPServer().ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatal(err.Error())
 	}
 }
@@ -310,17 +453,73 @@ func (n *Node) listenAndServeWSTLS() {
 		n.handler.handleWS(conn)
 	})
 
-	if err := http.ListenAndServeTLS(n.ClientAddr, n.TSLCertificate, n.TSLKey, nil); err != nil {
+	if err := n.newHTTPServer().ListenAndServeTLS(n.TSLCertificate, n.TSLKey); err != nil && err != http.ErrServerClosed {
 		log.Fatal(err.Error())
 	}
 }
 
+func (n *Node) newHTTPServer() *http.Server {
+	n.Lock()
+	defer n.Unlock()
+
+	n.httpServer = &http.Server{Addr: n.ClientAddr}
+	return n.httpServer
+}
+
 func (n *Node) storeSession(s *session.Session) {
 	n.Lock()
 	n.sessions[s.ID()] = s
 	n.Unlock()
 }
 
+func (n *Node) removeSession(s *session.Session) {
+	n.Lock()
+	delete(n.sessions, s.ID())
+	n.Unlock()
+}
+
+// closeSession notifies all members that the session of current gate has been
+// closed and fires the session closed callbacks
+func (n *Node) closeSession(s *session.Session, reason session.CloseReason) {
+	request := &clusterpb.SessionClosedRequest{
+		SessionId: s.ID(),
+		GateAddr:  n.ServiceAddr,
+		Reason:    int32(reason),
+	}
+	n.directory.remove(n.ServiceAddr, s.ID())
+
+	members := n.cluster.remoteAddrs()
+	for _, remote := range members {
+		log.Println("Notify remote server success", remote)
+		if n.StreamTransport {
+			err := n.sendStream(remote, &clusterpb.StreamMessage{
+				Message: &clusterpb.StreamMessage_SessionClosed{SessionClosed: request},
+			})
+			if err != nil {
+				log.Println("Cannot closed session in remote address", remote, err)
+			}
+			continue
+		}
+		pool, err := n.rpcClient.getConnPool(remote)
+		if err != nil {
+			log.Println("Cannot retrieve connection pool for address", remote, err)
+			continue
+		}
+		client := clusterpb.NewMemberClient(pool.Get())
+		_, err = client.SessionClosed(context.Background(), request)
+		if err != nil {
+			log.Println("Cannot closed session in remote address", remote, err)
+			continue
+		}
+		if env.Debug {
+			log.Println("Notify remote server success", remote)
+		}
+	}
+
+	n.removeSession(s)
+	scheduler.PushTask(func() { session.Lifetime.CloseWithReason(s, reason) })
+}
+
 func (n *Node) findSession(sid int64) *session.Session {
 	n.RLock()
 	s := n.sessions[sid]
@@ -342,12 +541,17 @@ func (n *Node) findOrCreateSession(sid int64, gateAddr string) (*session.Session
 			gateClient: clusterpb.NewMemberClient(conns.Get()),
 			rpcHandler: n.handler.remoteProcess,
 			gateAddr:   gateAddr,
+			node:       n,
 		}
 		s = session.New(ac)
+		if n.SessionStore != nil {
+			s.SetStore(n.SessionStore)
+		}
 		ac.session = s
 		n.Lock()
 		n.sessions[sid] = s
 		n.Unlock()
+		scheduler.PushTask(func() { session.Lifetime.Create(s) })
 	}
 	return s, nil
 }
@@ -355,11 +559,15 @@ func (n *Node) findOrCreateSession(sid int64, gateAddr string) (*session.Session
 func (n *Node) HandleRequest(_ context.Context, req *clusterpb.RequestMessage) (*clusterpb.MemberHandleResponse, error) {
 	handler, found := n.handler.localHandlers[req.Route]
 	if !found {
-		return nil, fmt.Errorf("service not found in current node: %v", req.Route)
+		err := errcode.Newf(errcode.NotFound, "service not found in current node: %v", req.Route)
+		return n.failRequest(req, err)
 	}
 	s, err := n.findOrCreateSession(req.SessionId, req.GateAddr)
 	if err != nil {
-		return nil, err
+		return n.failRequest(req, err)
+	}
+	if err := n.synchronize(s, req.Uid, req.BindVersion, req.State); err != nil {
+		return n.failRequest(req, err)
 	}
 	msg := &message.Message{
 		Type:  message.Request,
@@ -367,7 +575,38 @@ func (n *Node) HandleRequest(_ context.Context, req *clusterpb.RequestMessage) (
 		Route: req.Route,
 		Data:  req.Data,
 	}
-	n.handler.localProcess(handler, req.Id, s, msg)
+	n.handler.localProcess(handler, req.Id, s, msg, metaFromRemote(req.Timeout, req.Metadata))
+	return &clusterpb.MemberHandleResponse{}, nil
+}
+
+// failRequest replies the error to the client via the gate, so that the gate
+// does not reply it again. The error is returned if it cannot be replied.
+func (n *Node) failRequest(req *clusterpb.RequestMessage, err error) (*clusterpb.MemberHandleResponse, error) {
+	log.Println(fmt.Sprintf("Handle request (%d:%s) failed: %v", req.Id, req.Route, err))
+	data, e := json.Marshal(errcode.FromError(err, errcode.Internal))
+	if e != nil {
+		return nil, err
+	}
+	resp := &clusterpb.ResponseMessage{
+		SessionId: req.SessionId,
+		Id:        req.Id,
+		Data:      data,
+		Error:     true,
+	}
+	if n.StreamTransport {
+		e = n.sendStream(req.GateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Response{Response: resp},
+		})
+	} else {
+		var pool *connPool
+		pool, e = n.rpcClient.getConnPool(req.GateAddr)
+		if e == nil {
+			_, e = clusterpb.NewMemberClient(pool.Get()).HandleResponse(context.Background(), resp)
+		}
+	}
+	if e != nil {
+		return nil, err
+	}
 	return &clusterpb.MemberHandleResponse{}, nil
 }
 
@@ -380,12 +619,15 @@ func (n *Node) HandleNotify(_ context.Context, req *clusterpb.NotifyMessage) (*c
 	if err != nil {
 		return nil, err
 	}
+	if err := n.synchronize(s, req.Uid, req.BindVersion, req.State); err != nil {
+		return nil, err
+	}
 	msg := &message.Message{
 		Type:  message.Notify,
 		Route: req.Route,
 		Data:  req.Data,
 	}
-	n.handler.localProcess(handler, 0, s, msg)
+	n.handler.localProcess(handler, 0, s, msg, metaFromRemote(req.Timeout, req.Metadata))
 	return &clusterpb.MemberHandleResponse{}, nil
 }
 
@@ -402,29 +644,50 @@ func (n *Node) HandleResponse(_ context.Context, req *clusterpb.ResponseMessage)
 	if s == nil {
 		return &clusterpb.MemberHandleResponse{}, fmt.Errorf("session not found: %v", req.SessionId)
 	}
+	if req.Error {
+		return &clusterpb.MemberHandleResponse{}, responseError(s, req.Id, req.Data)
+	}
 	return &clusterpb.MemberHandleResponse{}, s.ResponseMID(req.Id, req.Data)
 }
 
+// NewMember implements the MemberServer interface, which is called by master
+// when a member joined the cluster
 func (n *Node) NewMember(_ context.Context, req *clusterpb.NewMemberRequest) (*clusterpb.NewMemberResponse, error) {
-	n.handler.addRemoteService(req.MemberInfo)
-	n.cluster.addMember(req.MemberInfo)
+	n.onMemberEvent(MemberEvent{Type: MemberAdded, Member: req.MemberInfo})
 	return &clusterpb.NewMemberResponse{}, nil
 }
 
+// DelMember implements the MemberServer interface, which is called by master
+// when a member left the cluster
 func (n *Node) DelMember(_ context.Context, req *clusterpb.DelMemberRequest) (*clusterpb.DelMemberResponse, error) {
-	n.handler.delMember(req.ServiceAddr)
-	n.cluster.delMember(req.ServiceAddr)
+	n.onMemberEvent(MemberEvent{Type: MemberRemoved, Member: &clusterpb.MemberInfo{ServiceAddr: req.ServiceAddr}})
 	return &clusterpb.DelMemberResponse{}, nil
 }
 
+// rebindSessions removes the router bindings of all sessions which bound to the
+// removed member, the next message will be routed to another available member
+func (n *Node) rebindSessions(addr string) {
+	n.RLock()
+	defer n.RUnlock()
+
+	for _, s := range n.sessions {
+		s.Router().UnbindAddress(addr)
+	}
+}
+
 // SessionClosed implements the MemberServer interface
 func (n *Node) SessionClosed(_ context.Context, req *clusterpb.SessionClosedRequest) (*clusterpb.SessionClosedResponse, error) {
+	if req.GateAddr != "" {
+		n.directory.remove(req.GateAddr, req.SessionId)
+	}
+
 	n.Lock()
 	s, found := n.sessions[req.SessionId]
 	delete(n.sessions, req.SessionId)
 	n.Unlock()
 	if found {
-		scheduler.PushTask(func() { session.Lifetime.Close(s) })
+		reason := session.CloseReason(req.Reason)
+		scheduler.PushTask(func() { session.Lifetime.CloseWithReason(s, reason) })
 	}
 	return &clusterpb.SessionClosedResponse{}, nil
 }
@@ -440,3 +703,15 @@ func (n *Node) CloseSession(_ context.Context, req *clusterpb.CloseSessionReques
 	}
 	return &clusterpb.CloseSessionResponse{}, nil
 }
+
+// KickSession implements the MemberServer interface
+func (n *Node) KickSession(_ context.Context, req *clusterpb.KickSessionRequest) (*clusterpb.KickSessionResponse, error) {
+	s := n.findSession(req.SessionId)
+	if s == nil {
+		return &clusterpb.KickSessionResponse{}, nil
+	}
+	if err := s.Kick(req.Data); err != nil {
+		return nil, err
+	}
+	return &clusterpb.KickSessionResponse{}, nil
+}
@@ -1,26 +1,74 @@
 package cluster_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt"
 	. "github.com/pingcap/check"
 	"github.com/revzim/nano/benchmark/io"
 	"github.com/revzim/nano/benchmark/testdata"
 	"github.com/revzim/nano/cluster"
 	"github.com/revzim/nano/component"
+	"github.com/revzim/nano/errcode"
+	"github.com/revzim/nano/internal/codec"
+	"github.com/revzim/nano/internal/env"
+	"github.com/revzim/nano/internal/message"
+	"github.com/revzim/nano/internal/packet"
+	"github.com/revzim/nano/pipeline"
 	"github.com/revzim/nano/scheduler"
 	"github.com/revzim/nano/session"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 )
 
 type nodeSuite struct{}
 
+// chJoined receives the sessions joined to GameComponent
+var chJoined = make(chan *session.Session, 10)
+
+// chAuthed receives the sessions authenticated by GateComponent
+var chAuthed = make(chan *session.Session, 10)
+
+// chContext receives the contexts and sessions of the handlers accepting a context
+var chContext = make(chan contextCall, 10)
+
+type contextCall struct {
+	ctx     context.Context
+	session *session.Session
+}
+
 var _ = Suite(&nodeSuite{})
 
 type (
 	MasterComponent struct{ component.Base }
 	GateComponent   struct{ component.Base }
 	GameComponent   struct{ component.Base }
+
+	// CallerComponent calls the handlers of cluster by node
+	CallerComponent struct {
+		component.Base
+		node *cluster.Node
+	}
 )
 
 func (c *MasterComponent) Test(session *session.Session, _ []byte) error {
@@ -35,6 +83,15 @@ func (c *GateComponent) Test2(session *session.Session, ping *testdata.Ping) err
 	return session.Response(&testdata.Pong{Content: "gate server pong2"})
 }
 
+func (c *GateComponent) Auth(session *session.Session, _ []byte) error {
+	if err := session.Bind(2001); err != nil {
+		return err
+	}
+	session.Set("nickname", "nano")
+	chAuthed <- session
+	return nil
+}
+
 func (c *GameComponent) Test(session *session.Session, _ []byte) error {
 	return session.Push("test", &testdata.Pong{Content: "game server pong"})
 }
@@ -43,14 +100,162 @@ func (c *GameComponent) Test2(session *session.Session, ping *testdata.Ping) err
 	return session.Response(&testdata.Pong{Content: "game server pong2"})
 }
 
+func (c *GateComponent) Echo(session *session.Session, ping *testdata.Ping) (*testdata.Pong, error) {
+	if ping.Content == "fail" {
+		return nil, errors.New("gate server failed")
+	}
+	return &testdata.Pong{Content: "gate " + ping.Content}, nil
+}
+
+func (c *GameComponent) Echo(session *session.Session, ping *testdata.Ping) (*testdata.Pong, error) {
+	if ping.Content == "fail" {
+		return nil, errors.New("game server failed")
+	}
+	if ping.Content == "missing" {
+		return nil, errcode.New(errcode.NotFound, "room not found").WithDetails(map[string]int{"room": 1})
+	}
+	return &testdata.Pong{Content: "game " + ping.Content}, nil
+}
+
+func (c *GateComponent) Trace(ctx context.Context, s *session.Session, ping *testdata.Ping) error {
+	chContext <- contextCall{ctx: ctx, session: s}
+	ctx = session.WithMetadata(ctx, map[string]string{"trace": ping.Content})
+	return s.RPCContext(ctx, "GameComponent.Trace", ping)
+}
+
+func (c *GameComponent) Trace(ctx context.Context, s *session.Session, ping *testdata.Ping) error {
+	chContext <- contextCall{ctx: ctx, session: s}
+	return nil
+}
+
+func (c *GameComponent) Fail(session *session.Session, ping *testdata.Ping) error {
+	return errors.New("game server failed")
+}
+
+func (c *GameComponent) Panic(session *session.Session, ping *testdata.Ping) error {
+	panic("game server panic")
+}
+
+func (c *GameComponent) Silent(session *session.Session, _ []byte) error {
+	return nil
+}
+
+func (c *GameComponent) Join(session *session.Session, _ []byte) error {
+	chJoined <- session
+	return nil
+}
+
+func (c *GameComponent) Login(session *session.Session, ping *testdata.Ping) error {
+	if err := session.Bind(1001); err != nil {
+		return err
+	}
+	return session.Response(&testdata.Pong{Content: "login"})
+}
+
+func (c *GateComponent) Grant(session *session.Session, ping *testdata.Ping) error {
+	if session.UID() == 0 {
+		if err := session.Bind(3001); err != nil {
+			return err
+		}
+	}
+	session.Set(component.ClaimsKey, jwt.MapClaims{component.RolesKey: []interface{}{ping.Content}})
+	return session.Response(&testdata.Pong{Content: "granted"})
+}
+
+// Ask calls the route before the first '>' of content with the rest of content
+func (c *CallerComponent) Ask(ctx context.Context, s *session.Session, ping *testdata.Ping) (*testdata.Pong, error) {
+	parts := strings.SplitN(ping.Content, ">", 2)
+	pong := &testdata.Pong{}
+	if err := c.node.Call(ctx, parts[0], &testdata.Ping{Content: parts[len(parts)-1]}, pong); err != nil {
+		return nil, err
+	}
+	return pong, nil
+}
+
+// Detach calls the route like Ask without the context of handler
+func (c *CallerComponent) Detach(s *session.Session, ping *testdata.Ping) (*testdata.Pong, error) {
+	parts := strings.SplitN(ping.Content, ">", 2)
+	pong := &testdata.Pong{}
+	if err := c.node.Call(context.Background(), parts[0], &testdata.Ping{Content: parts[len(parts)-1]}, pong); err != nil {
+		return nil, err
+	}
+	return pong, nil
+}
+
 func TestNode(t *testing.T) {
 	TestingT(t)
 }
 
-func (s *nodeSuite) TestNodeStartup(c *C) {
+func (s *nodeSuite) SetUpSuite(c *C) {
 	go scheduler.Sched()
-	defer scheduler.Close()
+}
+
+func (s *nodeSuite) TearDownSuite(c *C) {
+	scheduler.Close()
+}
 
+// startNode starts a node serving the component and the components of opts,
+// the caller shuts it down
+func startNode(c *C, addr string, opts cluster.Options, comp component.Component, compOpts ...component.Option) *cluster.Node {
+	if opts.Components == nil {
+		opts.Components = &component.Components{}
+	}
+	opts.Components.Register(comp, compOpts...)
+	node := &cluster.Node{Options: opts, ServiceAddr: addr}
+	c.Assert(node.Startup(), IsNil)
+	return node
+}
+
+// dialClient connects a client to the gate and waits for the handshake, the
+// setups are applied before connecting. It retries because the client listener
+// is started asynchronously.
+func dialClient(c *C, addr string, setups ...func(connector *io.Connector)) *io.Connector {
+	connector := io.NewConnector()
+	for _, setup := range setups {
+		setup(connector)
+	}
+	chWait := make(chan struct{}, 1)
+	connector.OnConnected(func() {
+		chWait <- struct{}{}
+	})
+
+	deadline := time.Now().Add(time.Second)
+	err := connector.Start(addr)
+	for err != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		err = connector.Start(addr)
+	}
+	c.Assert(err, IsNil)
+	<-chWait
+	return connector
+}
+
+// request sends a request and returns the response, which is the []byte of
+// response or *io.ResponseError
+func request(c *C, connector *io.Connector, route, content string) interface{} {
+	onResult := make(chan interface{}, 1)
+	err := connector.Request(route, &testdata.Ping{Content: content}, func(data interface{}) {
+		onResult <- data
+	})
+	c.Assert(err, IsNil)
+	return <-onResult
+}
+
+// requestContent returns the response of request, which must not be an error
+func requestContent(c *C, connector *io.Connector, route, content string) string {
+	data, ok := request(c, connector, route, content).([]byte)
+	c.Assert(ok, IsTrue)
+	return string(data)
+}
+
+// requestError returns the error response envelope of request
+func requestError(c *C, connector *io.Connector, route, content string) string {
+	resErr, ok := request(c, connector, route, content).(*io.ResponseError)
+	c.Assert(ok, IsTrue)
+	return string(resErr.Data)
+}
+
+func (s *nodeSuite) TestNodeStartup(c *C) {
 	masterComps := &component.Components{}
 	masterComps.Register(&MasterComponent{})
 	masterNode := &cluster.Node{
@@ -142,3 +347,1079 @@ func (s *nodeSuite) TestNodeStartup(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(strings.Contains(<-onResult, "master server pong"), IsTrue)
 }
+
+func (s *nodeSuite) TestMasterRestart(c *C) {
+	newMaster := func() *cluster.Node {
+		masterComps := &component.Components{}
+		masterComps.Register(&MasterComponent{})
+		masterNode := &cluster.Node{
+			Options: cluster.Options{
+				IsMaster:        true,
+				Components:      masterComps,
+				MemberHeartbeat: 100 * time.Millisecond,
+			},
+			ServiceAddr: "127.0.0.1:4470",
+		}
+		c.Assert(masterNode.Startup(), IsNil)
+		return masterNode
+	}
+	masterNode := newMaster()
+
+	memberComps := &component.Components{}
+	memberComps.Register(&GameComponent{})
+	memberNode := &cluster.Node{
+		Options: cluster.Options{
+			AdvertiseAddr:   "127.0.0.1:4470",
+			Components:      memberComps,
+			MemberHeartbeat: 100 * time.Millisecond,
+		},
+		ServiceAddr: "127.0.0.1:14471",
+	}
+	c.Assert(memberNode.Startup(), IsNil)
+	defer memberNode.Shutdown()
+	c.Assert(masterNode.Handler().RemoteService(), DeepEquals, []string{"GameComponent"})
+
+	// The restarted master knows nothing about the member until it registers again
+	masterNode.Shutdown()
+	masterNode = newMaster()
+	defer masterNode.Shutdown()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for len(masterNode.Handler().RemoteService()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	c.Assert(masterNode.Handler().RemoteService(), DeepEquals, []string{"GameComponent"})
+	c.Assert(memberNode.Handler().RemoteService(), DeepEquals, []string{"MasterComponent"})
+}
+
+func (s *nodeSuite) TestMemoryDiscovery(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14481", cluster.Options{
+		ClientAddr: "127.0.0.1:14482",
+		Discovery:  discovery,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14483", cluster.Options{Discovery: discovery}, &GameComponent{})
+
+	c.Assert(gateNode.Handler().RemoteService(), DeepEquals, []string{"GameComponent"})
+	c.Assert(gameNode.Handler().RemoteService(), DeepEquals, []string{"GateComponent"})
+
+	gameNode.Shutdown()
+	c.Assert(gateNode.Handler().RemoteService(), HasLen, 0)
+
+	// Shutdown is idempotent
+	gameNode.Shutdown()
+}
+
+func (s *nodeSuite) TestCall(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gameNode := startNode(c, "127.0.0.1:14491", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	gateNode := startNode(c, "127.0.0.1:14492", cluster.Options{Discovery: discovery}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	pong := &testdata.Pong{}
+	err := gateNode.Call(context.Background(), "GameComponent.Test2", &testdata.Ping{}, pong)
+	c.Assert(err, IsNil)
+	c.Assert(pong.Content, Equals, "game server pong2")
+
+	// local handler
+	pong = &testdata.Pong{}
+	err = gateNode.Call(context.Background(), "GateComponent.Test2", &testdata.Ping{}, pong)
+	c.Assert(err, IsNil)
+	c.Assert(pong.Content, Equals, "gate server pong2")
+
+	err = gateNode.Call(context.Background(), "GameComponent.Fail", &testdata.Ping{}, nil)
+	remoteErr, ok := err.(*cluster.RemoteError)
+	c.Assert(ok, IsTrue)
+	c.Assert(remoteErr.Message, Equals, "game server failed")
+
+	// The call is finished when the handler replied nothing
+	err = gateNode.Call(context.Background(), "GameComponent.Silent", []byte{}, nil)
+	c.Assert(err, IsNil)
+}
+
+func (s *nodeSuite) TestReentrantCall(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateComps := &component.Components{}
+	gateComps.Register(&GateComponent{})
+	gateCaller := &CallerComponent{}
+	gateNode := startNode(c, "127.0.0.1:14681", cluster.Options{
+		Components: gateComps,
+		Discovery:  discovery,
+	}, gateCaller)
+	defer gateNode.Shutdown()
+	gateCaller.node = gateNode
+
+	gameCaller := &CallerComponent{}
+	gameNode := startNode(c, "127.0.0.1:14682", cluster.Options{Discovery: discovery}, gameCaller, component.WithName("GameCaller"))
+	defer gameNode.Shutdown()
+	gameCaller.node = gameNode
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The handler calls a handler of the same node
+	pong := &testdata.Pong{}
+	err := gateNode.Call(ctx, "CallerComponent.Ask", &testdata.Ping{Content: "GateComponent.Echo>local"}, pong)
+	c.Assert(err, IsNil)
+	c.Assert(pong.Content, Equals, "gate local")
+
+	// The handler of game node calls back the gate whose handler is waiting
+	err = gateNode.Call(ctx, "CallerComponent.Ask", &testdata.Ping{Content: "GameCaller.Ask>GateComponent.Echo>callback"}, pong)
+	c.Assert(err, IsNil)
+	c.Assert(pong.Content, Equals, "gate callback")
+
+	// The handlers calling without the context of handler fail instead of blocking
+	// the scheduler
+	err = gateNode.Call(ctx, "CallerComponent.Detach", &testdata.Ping{Content: "GateComponent.Echo>local"}, pong)
+	remoteErr, ok := err.(*cluster.RemoteError)
+	c.Assert(ok, IsTrue)
+	c.Assert(remoteErr.Message, Equals, cluster.ErrCallOnScheduler.Error())
+	err = gateNode.Call(ctx, "CallerComponent.Ask", &testdata.Ping{Content: "GateComponent.Echo>scheduled"}, pong)
+	c.Assert(err, IsNil)
+	c.Assert(pong.Content, Equals, "gate scheduled")
+}
+
+func (s *nodeSuite) TestStreamTransport(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14501", cluster.Options{
+		ClientAddr:      "127.0.0.1:14502",
+		Discovery:       discovery,
+		StreamTransport: true,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14503", cluster.Options{
+		Discovery:       discovery,
+		StreamTransport: true,
+	}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14502")
+	defer connector.Close()
+
+	onResult := make(chan string, 10)
+	onData := func(data interface{}) {
+		pong := &testdata.Pong{}
+		c.Check(env.Serializer.Unmarshal(data.([]byte), pong), IsNil)
+		onResult <- pong.Content
+	}
+	connector.On("test", onData)
+
+	// The messages of a session keep in order over the stream
+	for i := 0; i < 5; i++ {
+		err := connector.Notify("GameComponent.Test", &testdata.Ping{Content: "ping"})
+		c.Assert(err, IsNil)
+		err = connector.Request("GameComponent.Test2", &testdata.Ping{Content: "ping"}, onData)
+		c.Assert(err, IsNil)
+	}
+	for i := 0; i < 5; i++ {
+		c.Assert(<-onResult, Equals, "game server pong")
+		c.Assert(<-onResult, Equals, "game server pong2")
+	}
+}
+
+// writeCertificates writes a CA certificate and a certificate signed by it for 127.0.0.1
+func writeCertificates(c *C, dir string) (certFile, keyFile, caFile string) {
+	writePEM := func(name, typ string, der []byte) string {
+		path := filepath.Join(dir, name)
+		err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600)
+		c.Assert(err, IsNil)
+		return path
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "nano test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	c.Assert(err, IsNil)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "nano test member"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	c.Assert(err, IsNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, IsNil)
+
+	return writePEM("member.crt", "CERTIFICATE", der),
+		writePEM("member.key", "EC PRIVATE KEY", keyDER),
+		writePEM("ca.crt", "CERTIFICATE", caDER)
+}
+
+func (s *nodeSuite) TestClusterCredentials(c *C) {
+	config, err := cluster.NewClusterTLSConfig(writeCertificates(c, c.MkDir()))
+	c.Assert(err, IsNil)
+
+	discovery := cluster.NewMemoryDiscovery()
+
+	gameNode := startNode(c, "127.0.0.1:14511", cluster.Options{
+		Discovery:    discovery,
+		ClusterTLS:   config,
+		ClusterToken: "secret",
+	}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	gateNode := startNode(c, "127.0.0.1:14512", cluster.Options{
+		Discovery:    discovery,
+		ClusterTLS:   config,
+		ClusterToken: "secret",
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	pong := &testdata.Pong{}
+	err = gateNode.Call(context.Background(), "GameComponent.Test2", &testdata.Ping{}, pong)
+	c.Assert(err, IsNil)
+	c.Assert(pong.Content, Equals, "game server pong2")
+
+	// The member with a wrong token is rejected
+	intruderNode := startNode(c, "127.0.0.1:14513", cluster.Options{
+		Discovery:    discovery,
+		ClusterTLS:   config,
+		ClusterToken: "guess",
+	}, &MasterComponent{})
+	defer intruderNode.Shutdown()
+
+	err = intruderNode.Call(context.Background(), "GameComponent.Test2", &testdata.Ping{}, pong)
+	c.Assert(status.Code(err), Equals, codes.Unauthenticated)
+
+	// The transport credentials passed by grpc options are kept
+	grpcOptions := env.GrpcOptions
+	env.GrpcOptions = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(config))}
+	defer func() { env.GrpcOptions = grpcOptions }()
+
+	optionNode := startNode(c, "127.0.0.1:14514", cluster.Options{
+		Discovery:    discovery,
+		ClusterToken: "secret",
+	}, &MasterComponent{})
+	defer optionNode.Shutdown()
+
+	pong = &testdata.Pong{}
+	err = optionNode.Call(context.Background(), "GameComponent.Test2", &testdata.Ping{}, pong)
+	c.Assert(err, IsNil)
+	c.Assert(pong.Content, Equals, "game server pong2")
+}
+
+func (s *nodeSuite) TestDrain(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14521", cluster.Options{
+		ClientAddr: "127.0.0.1:14522",
+		Discovery:  discovery,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14523", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14522")
+	defer connector.Close()
+
+	// The client is kicked and the drain finishes before timeout
+	start := time.Now()
+	gateNode.Drain(5 * time.Second)
+	c.Assert(time.Since(start) < 5*time.Second, IsTrue)
+
+	// The gate does not accept new connections
+	err := io.NewConnector().Start("127.0.0.1:14522")
+	c.Assert(err, NotNil)
+
+	// Other members know the gate is draining
+	members, err := discovery.List()
+	c.Assert(err, IsNil)
+	for _, m := range members {
+		c.Assert(m.Draining, Equals, m.ServiceAddr == gateNode.ServiceAddr)
+	}
+}
+
+func (s *nodeSuite) TestSessionDirectory(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14531", cluster.Options{
+		ClientAddr: "127.0.0.1:14532",
+		Discovery:  discovery,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14533", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14532")
+	defer connector.Close()
+
+	onResult := make(chan string, 10)
+	connector.On("notice", func(data interface{}) {
+		onResult <- string(data.([]byte))
+	})
+	c.Assert(strings.Contains(requestContent(c, connector, "GameComponent.Login", ""), "login"), IsTrue)
+
+	// The member joined after binding finds the session from other members
+	chatNode := startNode(c, "127.0.0.1:14534", cluster.Options{Discovery: discovery}, &MasterComponent{})
+	defer chatNode.Shutdown()
+
+	err := chatNode.PushToUID(1001, "notice", &testdata.Pong{Content: "friend online"})
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(<-onResult, "friend online"), IsTrue)
+
+	err = chatNode.PushToUID(1002, "notice", &testdata.Pong{Content: "friend online"})
+	c.Assert(err, Equals, cluster.ErrUIDNotFound)
+
+	// The client is kicked with the reason and the binding is removed after the
+	// session closed
+	chKicked := make(chan string, 1)
+	connector.OnKicked(func(data []byte) {
+		chKicked <- string(data)
+	})
+	err = gameNode.KickUID(1001, map[string]string{"reason": "banned"})
+	c.Assert(err, IsNil)
+	c.Assert(<-chKicked, Equals, `{"reason":"banned"}`)
+	deadline := time.Now().Add(time.Second)
+	for err == nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		err = gateNode.PushToUID(1001, "notice", &testdata.Pong{Content: "friend online"})
+	}
+	c.Assert(err, Equals, cluster.ErrUIDNotFound)
+}
+
+func (s *nodeSuite) TestMulticast(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14541", cluster.Options{
+		ClientAddr: "127.0.0.1:14542",
+		Discovery:  discovery,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14543", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	onResult := make(chan string, 10)
+	var sessions []*session.Session
+	for i := 0; i < 3; i++ {
+		connector := dialClient(c, "127.0.0.1:14542")
+		defer connector.Close()
+
+		connector.On("notice", func(data interface{}) {
+			onResult <- string(data.([]byte))
+		})
+		err := connector.Notify("GameComponent.Join", &testdata.Ping{})
+		c.Assert(err, IsNil)
+		sessions = append(sessions, <-chJoined)
+	}
+
+	// The sessions on game node are pushed through the gate
+	err := cluster.Multicast(sessions, "notice", &testdata.Pong{Content: "world message"})
+	c.Assert(err, IsNil)
+	for i := 0; i < 3; i++ {
+		c.Assert(strings.Contains(<-onResult, "world message"), IsTrue)
+	}
+}
+
+func (s *nodeSuite) TestSessionResume(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14551", cluster.Options{
+		ClientAddr:    "127.0.0.1:14552",
+		Discovery:     discovery,
+		SessionResume: 5 * time.Second,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14553", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	connect := func(token string) *io.Connector {
+		return dialClient(c, "127.0.0.1:14552", func(connector *io.Connector) {
+			connector.SetResumeToken(token)
+		})
+	}
+
+	connector := connect("")
+	token := connector.ResumeToken()
+	c.Assert(token, Not(Equals), "")
+
+	c.Assert(strings.Contains(requestContent(c, connector, "GameComponent.Login", ""), "login"), IsTrue)
+	err := connector.Notify("GameComponent.Join", &testdata.Ping{})
+	c.Assert(err, IsNil)
+	joined := <-chJoined
+
+	// The connection is broken and the client reconnects with the resume token
+	connector.Close()
+	time.Sleep(100 * time.Millisecond)
+	connector = connect(token)
+	defer connector.Close()
+	c.Assert(connector.ResumeToken(), Not(Equals), token)
+
+	err = connector.Notify("GameComponent.Join", &testdata.Ping{})
+	c.Assert(err, IsNil)
+	resumed := <-chJoined
+	c.Assert(resumed, Equals, joined)
+	c.Assert(resumed.UID(), Equals, int64(1001))
+
+	onResult := make(chan string, 10)
+	connector.On("notice", func(data interface{}) {
+		onResult <- string(data.([]byte))
+	})
+	err = gateNode.PushToUID(1001, "notice", &testdata.Pong{Content: "welcome back"})
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(<-onResult, "welcome back"), IsTrue)
+
+	// The resume token can only be used once
+	stale := connect(token)
+	defer stale.Close()
+	err = stale.Notify("GameComponent.Join", &testdata.Ping{})
+	c.Assert(err, IsNil)
+	c.Assert(<-chJoined, Not(Equals), joined)
+}
+
+func (s *nodeSuite) TestDuplicateLogin(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14561", cluster.Options{
+		ClientAddr: "127.0.0.1:14562",
+		Discovery:  discovery,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14563", cluster.Options{
+		Discovery:      discovery,
+		DuplicateLogin: cluster.KickOld,
+	}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	chReplaced := make(chan [2]*session.Session, 1)
+	session.Lifetime.OnReplaced(func(old, new *session.Session) {
+		chReplaced <- [2]*session.Session{old, new}
+	})
+
+	login := func() (*io.Connector, *session.Session) {
+		connector := dialClient(c, "127.0.0.1:14562")
+		err := connector.Notify("GameComponent.Join", &testdata.Ping{})
+		c.Assert(err, IsNil)
+		joined := <-chJoined
+		c.Assert(strings.Contains(requestContent(c, connector, "GameComponent.Login", ""), "login"), IsTrue)
+		return connector, joined
+	}
+
+	first, old := login()
+	defer first.Close()
+	chKicked := make(chan string, 1)
+	first.OnKicked(func(data []byte) {
+		chKicked <- string(data)
+	})
+
+	second, current := login()
+	defer second.Close()
+	c.Assert(<-chKicked, Equals, `{"reason":"duplicate login"}`)
+	c.Assert(<-chReplaced, Equals, [2]*session.Session{old, current})
+	c.Assert(current.UID(), Equals, int64(1001))
+}
+
+func (s *nodeSuite) TestLifetimeEvents(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14571", cluster.Options{
+		ClientAddr: "127.0.0.1:14572",
+		Discovery:  discovery,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14573", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	// The global listeners are called for the sessions of all tests
+	chHandshake := make(chan *session.Session, 10)
+	session.Lifetime.OnHandshake(func(s *session.Session) {
+		select {
+		case chHandshake <- s:
+		default:
+		}
+	})
+
+	connector := dialClient(c, "127.0.0.1:14572")
+
+	chEvents := make(chan string, 10)
+	gate := <-chHandshake
+	gate.Lifetime().OnClosed(func(s *session.Session) {
+		chEvents <- "gate closed by " + s.CloseReason().String()
+	})
+
+	err := connector.Notify("GameComponent.Join", &testdata.Ping{})
+	c.Assert(err, IsNil)
+	game := <-chJoined
+	game.Lifetime().OnBind(func(s *session.Session, uid int64) {
+		chEvents <- fmt.Sprintf("game bound %d", uid)
+	})
+	game.Lifetime().OnClosed(func(s *session.Session) {
+		chEvents <- "game closed by " + s.CloseReason().String()
+	})
+
+	c.Assert(strings.Contains(requestContent(c, connector, "GameComponent.Login", ""), "login"), IsTrue)
+	c.Assert(<-chEvents, Equals, "game bound 1001")
+
+	connector.Close()
+	closed := []string{<-chEvents, <-chEvents}
+	sort.Strings(closed)
+	c.Assert(closed, DeepEquals, []string{"game closed by client", "gate closed by client"})
+}
+
+func (s *nodeSuite) TestSessionSync(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14581", cluster.Options{
+		ClientAddr: "127.0.0.1:14582",
+		Discovery:  discovery,
+		SyncKeys:   []string{"nickname", "level"},
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14583", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14582")
+	defer connector.Close()
+
+	err := connector.Notify("GateComponent.Auth", &testdata.Ping{})
+	c.Assert(err, IsNil)
+	gate := <-chAuthed
+
+	// The uid and synced keys are carried to the backend session
+	err = connector.Notify("GameComponent.Join", &testdata.Ping{})
+	c.Assert(err, IsNil)
+	game := <-chJoined
+	c.Assert(game.UID(), Equals, int64(2001))
+	c.Assert(game.String("nickname"), Equals, "nano")
+
+	// The backend writes the synced keys back to the gate session
+	game.Set("level", 10)
+	game.Set("scene", "lobby")
+	err = game.Sync("level")
+	c.Assert(err, IsNil)
+	c.Assert(gate.Int("level"), Equals, 10)
+	c.Assert(gate.HasKey("scene"), IsFalse)
+
+	// The synced keys removed from the gate session are removed from the backend
+	gate.Remove("nickname")
+	err = connector.Notify("GameComponent.Join", &testdata.Ping{})
+	c.Assert(err, IsNil)
+	c.Assert(<-chJoined, Equals, game)
+	c.Assert(game.HasKey("nickname"), IsFalse)
+	c.Assert(game.Int("level"), Equals, 10)
+	c.Assert(game.String("scene"), Equals, "lobby")
+}
+
+func (s *nodeSuite) TestLoginThenAuthenticated(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14711", cluster.Options{
+		ClientAddr: "127.0.0.1:14712",
+		Discovery:  discovery,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14713", cluster.Options{Discovery: discovery},
+		&GameComponent{}, component.WithMethodAccess("Echo", component.Authenticated))
+	defer gameNode.Shutdown()
+
+	// The request sent right after the login reply may be forwarded before the
+	// gate knows the uid bound by the backend
+	for i := 0; i < 10; i++ {
+		connector := dialClient(c, "127.0.0.1:14712")
+		c.Assert(strings.Contains(requestContent(c, connector, "GameComponent.Login", ""), "login"), IsTrue)
+		c.Assert(strings.Contains(requestContent(c, connector, "GameComponent.Echo", "ping"), "game ping"), IsTrue)
+		connector.Close()
+	}
+}
+
+func (s *nodeSuite) TestRateLimit(c *C) {
+	gateNode := startNode(c, "127.0.0.1:14591", cluster.Options{
+		ClientAddr: "127.0.0.1:14592",
+		Discovery:  cluster.NewMemoryDiscovery(),
+		RateLimit: &cluster.RateLimitOptions{
+			Session: cluster.RateLimit{Rate: 1, Burst: 2},
+			Action:  cluster.RateLimitKick,
+		},
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	chKicked := make(chan string, 1)
+	connector := dialClient(c, "127.0.0.1:14592", func(connector *io.Connector) {
+		connector.OnKicked(func(data []byte) {
+			chKicked <- string(data)
+		})
+	})
+	defer connector.Close()
+
+	for i := 0; i < 3; i++ {
+		err := connector.Notify("GateComponent.Test", &testdata.Ping{Content: "ping"})
+		c.Assert(err, IsNil)
+	}
+	c.Assert(<-chKicked, Equals, `{"reason":"rate limited"}`)
+	c.Assert(gateNode.RateLimitStats(), Equals, cluster.RateLimitStats{Allowed: 2, Kicked: 1})
+
+	// The dropped requests are replied with an error response
+	dropNode := startNode(c, "127.0.0.1:14701", cluster.Options{
+		ClientAddr: "127.0.0.1:14702",
+		Discovery:  cluster.NewMemoryDiscovery(),
+		RateLimit: &cluster.RateLimitOptions{
+			Session: cluster.RateLimit{Rate: 0.1, Burst: 1},
+			Action:  cluster.RateLimitDrop,
+		},
+	}, &GateComponent{})
+	defer dropNode.Shutdown()
+
+	dropped := dialClient(c, "127.0.0.1:14702")
+	defer dropped.Close()
+
+	c.Assert(strings.Contains(requestContent(c, dropped, "GateComponent.Test2", "ping"), "gate server pong2"), IsTrue)
+	c.Assert(requestError(c, dropped, "GateComponent.Test2", "ping"), Equals, `{"code":429,"message":"rate limited"}`)
+	c.Assert(dropNode.RateLimitStats(), Equals, cluster.RateLimitStats{Allowed: 1, Dropped: 1})
+}
+
+func (s *nodeSuite) TestAdmissionControl(c *C) {
+	gateNode := startNode(c, "127.0.0.1:14601", cluster.Options{
+		ClientAddr:       "127.0.0.1:14602",
+		Discovery:        cluster.NewMemoryDiscovery(),
+		HandshakeTimeout: 100 * time.Millisecond,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	// The connection is closed if it does not finish handshake in time
+	deadline := time.Now().Add(time.Second)
+	conn, err := net.Dial("tcp", "127.0.0.1:14602")
+	for err != nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		conn, err = net.Dial("tcp", "127.0.0.1:14602")
+	}
+	c.Assert(err, IsNil)
+	start := time.Now()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	data, err := ioutil.ReadAll(conn)
+	c.Assert(err, IsNil)
+	c.Assert(data, HasLen, 0)
+	c.Assert(time.Since(start) >= 90*time.Millisecond, IsTrue)
+	conn.Close()
+
+	cappedNode := startNode(c, "127.0.0.1:14603", cluster.Options{
+		ClientAddr:       "127.0.0.1:14604",
+		Discovery:        cluster.NewMemoryDiscovery(),
+		MaxSessionsPerIP: 1,
+	}, &GateComponent{})
+	defer cappedNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14604")
+	defer connector.Close()
+
+	// The connection exceeding the cap is kicked before the agent created
+	conn, err = net.Dial("tcp", "127.0.0.1:14604")
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	data, err = ioutil.ReadAll(conn)
+	c.Assert(err, IsNil)
+	c.Assert(data[0], Equals, byte(0x05))
+	c.Assert(string(data[4:]), Equals, `{"reason":"too many sessions from the same ip"}`)
+}
+
+func (s *nodeSuite) TestHandshakeMetadata(c *C) {
+	dict := message.Dictionary()
+	message.SetDictionary(map[string]uint16{"handshake.metadata": 0xff01})
+	env.HandshakeUserData = map[string]string{"motd": "welcome"}
+	defer func() {
+		env.HandshakeUserData = nil
+		message.ResetDictionary()
+		message.SetDictionary(dict)
+	}()
+
+	gateNode := startNode(c, "127.0.0.1:14611", cluster.Options{
+		ClientAddr: "127.0.0.1:14612",
+		Discovery:  cluster.NewMemoryDiscovery(),
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	type handshakeResponse struct {
+		Code int `json:"code"`
+		Sys  struct {
+			Version    string            `json:"version"`
+			Serializer string            `json:"serializer"`
+			ErrorEnc   string            `json:"errorEncoding"`
+			UseDict    bool              `json:"useDict"`
+			Dict       map[string]uint16 `json:"dict"`
+			DictHash   string            `json:"dictHash"`
+			DictVer    string            `json:"dictVersion"`
+		} `json:"sys"`
+		User map[string]string `json:"user"`
+	}
+	chHandshake := make(chan *handshakeResponse, 2)
+	onHandshake := func(connector *io.Connector) {
+		connector.OnHandshake(func(data []byte) {
+			res := &handshakeResponse{}
+			if err := json.Unmarshal(data, res); err == nil {
+				chHandshake <- res
+			}
+		})
+	}
+
+	connector := dialClient(c, "127.0.0.1:14612", onHandshake)
+	res := <-chHandshake
+	c.Assert(res.Code, Equals, 200)
+	c.Assert(res.Sys.Version, Not(Equals), "")
+	c.Assert(res.Sys.Serializer, Equals, "protobuf")
+	c.Assert(res.Sys.ErrorEnc, Equals, "json")
+	c.Assert(res.Sys.UseDict, IsTrue)
+	c.Assert(res.Sys.Dict["handshake.metadata"], Equals, uint16(0xff01))
+	c.Assert(res.Sys.DictHash, Equals, message.DictionaryHash())
+	c.Assert(res.Sys.DictVer, Equals, res.Sys.DictHash)
+	c.Assert(res.User["motd"], Equals, "welcome")
+	c.Assert(connector.Dictionary()["handshake.metadata"], Equals, uint16(0xff01))
+	connector.Close()
+
+	// The cached dictionary is not sent again
+	reconnector := dialClient(c, "127.0.0.1:14612", onHandshake, func(reconnector *io.Connector) {
+		reconnector.SetDictionary(connector.Dictionary(), res.Sys.DictHash)
+	})
+	defer reconnector.Close()
+
+	res = <-chHandshake
+	c.Assert(res.Sys.UseDict, IsTrue)
+	c.Assert(res.Sys.Dict, IsNil)
+	c.Assert(res.Sys.DictHash, Equals, message.DictionaryHash())
+
+	// The dictionary cached by pomelo clients is presented as dictVersion
+	conn, err := net.Dial("tcp", "127.0.0.1:14612")
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	hs, err := json.Marshal(map[string]interface{}{"sys": map[string]string{"dictVersion": res.Sys.DictHash}})
+	c.Assert(err, IsNil)
+	p, err := codec.Encode(packet.Handshake, hs)
+	c.Assert(err, IsNil)
+	_, err = conn.Write(p)
+	c.Assert(err, IsNil)
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 4096)
+	size, err := conn.Read(buf)
+	c.Assert(err, IsNil)
+	packets, err := codec.NewDecoder().Decode(buf[:size])
+	c.Assert(err, IsNil)
+	c.Assert(packets, HasLen, 1)
+	res = &handshakeResponse{}
+	c.Assert(json.Unmarshal(packets[0].Data, res), IsNil)
+	c.Assert(res.Sys.Dict, IsNil)
+	c.Assert(res.Sys.DictVer, Equals, message.DictionaryHash())
+}
+
+func (s *nodeSuite) TestAutoDictionary(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14621", cluster.Options{
+		ClientAddr:     "127.0.0.1:14622",
+		Discovery:      discovery,
+		AutoDictionary: true,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+	c.Assert(message.Dictionary()["GateComponent.Test2"], Not(Equals), uint16(0))
+
+	gameNode := startNode(c, "127.0.0.1:14623", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14622")
+	defer connector.Close()
+
+	// The routes of local handlers and remote members are sent to client
+	dict := connector.Dictionary()
+	c.Assert(dict["GateComponent.Test2"], Equals, message.Dictionary()["GateComponent.Test2"])
+	c.Assert(dict["GameComponent.Test2"], Equals, message.Dictionary()["GameComponent.Test2"])
+	c.Assert(dict["GameComponent.Test2"], Not(Equals), uint16(0))
+
+	// The compressed routes are routed to the handlers
+	c.Assert(strings.Contains(requestContent(c, connector, "GameComponent.Test2", "ping"), "game server pong2"), IsTrue)
+}
+
+func (s *nodeSuite) TestHandlerResponse(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14631", cluster.Options{
+		ClientAddr: "127.0.0.1:14632",
+		Discovery:  discovery,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14633", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14632")
+	defer connector.Close()
+
+	// The returned response is replied to the request
+	for _, route := range []string{"GateComponent.Echo", "GameComponent.Echo"} {
+		content := requestContent(c, connector, route, "ping")
+		c.Assert(strings.Contains(content, strings.ToLower(route[:4])+" ping"), IsTrue)
+	}
+
+	// The returned error is replied as an error response
+	for _, route := range []string{"GateComponent.Echo", "GameComponent.Echo", "GameComponent.Fail"} {
+		expected := fmt.Sprintf(`{"code":500,"message":"%s server failed"}`, strings.ToLower(route[:4]))
+		c.Assert(requestError(c, connector, route, "fail"), Equals, expected)
+	}
+
+	// The panic of handler is replied as an internal error
+	c.Assert(requestError(c, connector, "GameComponent.Panic", "ping"), Equals, `{"code":500,"message":"internal error"}`)
+
+	pong := &testdata.Pong{}
+	err := gateNode.Call(context.Background(), "GameComponent.Echo", &testdata.Ping{Content: "call"}, pong)
+	c.Assert(err, IsNil)
+	c.Assert(pong.Content, Equals, "game call")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = gateNode.Call(ctx, "GameComponent.Panic", &testdata.Ping{}, nil)
+	remoteErr, ok := err.(*cluster.RemoteError)
+	c.Assert(ok, IsTrue)
+	c.Assert(remoteErr.Code, Equals, errcode.Internal)
+}
+
+func (s *nodeSuite) TestErrorResponse(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	pipe := pipeline.New()
+	pipe.Inbound().PushBack(func(_ *session.Session, msg *message.Message) error {
+		if msg.Route == "GateComponent.Test2" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+	gateNode := startNode(c, "127.0.0.1:14641", cluster.Options{
+		ClientAddr: "127.0.0.1:14642",
+		Discovery:  discovery,
+		Pipeline:   pipe,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14643", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14642")
+	defer connector.Close()
+
+	// The handler chooses the code by errcode.Error
+	c.Assert(requestError(c, connector, "GameComponent.Echo", "missing"), Equals,
+		`{"code":404,"message":"room not found","details":{"room":1}}`)
+	c.Assert(requestError(c, connector, "UnknownComponent.Test", "ping"), Equals,
+		`{"code":404,"message":"route UnknownComponent.Test not found"}`)
+	c.Assert(requestError(c, connector, "GateComponent.Test2", "ping"), Equals,
+		`{"code":403,"message":"rejected"}`)
+
+	err := gateNode.Call(context.Background(), "GameComponent.Echo", &testdata.Ping{Content: "missing"}, nil)
+	remoteErr, ok := err.(*cluster.RemoteError)
+	c.Assert(ok, IsTrue)
+	c.Assert(remoteErr.Code, Equals, errcode.NotFound)
+	c.Assert(remoteErr.Message, Equals, "room not found")
+}
+
+func (s *nodeSuite) TestHandlerContext(c *C) {
+	discovery := cluster.NewMemoryDiscovery()
+
+	gateNode := startNode(c, "127.0.0.1:14651", cluster.Options{
+		ClientAddr:     "127.0.0.1:14652",
+		Discovery:      discovery,
+		RequestTimeout: 10 * time.Second,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14653", cluster.Options{Discovery: discovery}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14652")
+
+	err := connector.Notify("GateComponent.Trace", &testdata.Ping{Content: "t1"})
+	c.Assert(err, IsNil)
+
+	// The deadline of client message is set by RequestTimeout
+	gate := <-chContext
+	gateDeadline, ok := gate.ctx.Deadline()
+	c.Assert(ok, IsTrue)
+	c.Assert(time.Until(gateDeadline) > 5*time.Second, IsTrue)
+
+	// The deadline and metadata are propagated to the member
+	game := <-chContext
+	gameDeadline, ok := game.ctx.Deadline()
+	c.Assert(ok, IsTrue)
+	c.Assert(gameDeadline.Before(gateDeadline), IsFalse)
+	c.Assert(gameDeadline.Sub(gateDeadline) < time.Second, IsTrue)
+	c.Assert(session.MetadataFromContext(game.ctx)["trace"], Equals, "t1")
+
+	// The session context is cancelled when the client disconnected
+	connector.Close()
+	for _, s := range []*session.Session{gate.session, game.session} {
+		select {
+		case <-s.Context().Done():
+		case <-time.After(time.Second):
+			c.Fatal("session context is not cancelled")
+		}
+	}
+}
+
+func (s *nodeSuite) TestInterceptors(c *C) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) component.Interceptor {
+		return func(inv *component.Invocation, next component.Invoker) (interface{}, error) {
+			mu.Lock()
+			calls = append(calls, name+":"+inv.Route)
+			mu.Unlock()
+			return next(inv)
+		}
+	}
+	reject := func(inv *component.Invocation, next component.Invoker) (interface{}, error) {
+		if ping, ok := inv.Arg.(*testdata.Ping); ok && ping.Content == "deny" {
+			return nil, errcode.New(errcode.Unauthorized, "denied")
+		}
+		return next(inv)
+	}
+	// replace corrupts the invocation of "arg" and "context"
+	replace := func(inv *component.Invocation, next component.Invoker) (interface{}, error) {
+		if ping, ok := inv.Arg.(*testdata.Ping); ok {
+			switch ping.Content {
+			case "arg":
+				inv.Arg = "ping"
+			case "context":
+				inv.Context = nil
+			}
+		}
+		return next(inv)
+	}
+	decorate := func(inv *component.Invocation, next component.Invoker) (interface{}, error) {
+		resp, err := next(inv)
+		if pong, ok := resp.(*testdata.Pong); ok {
+			return &testdata.Pong{Content: pong.Content + "!"}, err
+		}
+		return resp, err
+	}
+
+	gateNode := startNode(c, "127.0.0.1:14661", cluster.Options{
+		ClientAddr:   "127.0.0.1:14662",
+		Discovery:    cluster.NewMemoryDiscovery(),
+		Interceptors: []component.Interceptor{record("global"), reject, replace},
+	}, &GateComponent{},
+		component.WithInterceptors(record("service")),
+		component.WithMethodInterceptors("Echo", record("method"), decorate))
+	defer gateNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14662")
+	defer connector.Close()
+
+	// The interceptors are called in order and can replace the response
+	c.Assert(strings.Contains(requestContent(c, connector, "GateComponent.Echo", "ping"), "gate ping!"), IsTrue)
+	mu.Lock()
+	c.Assert(calls, DeepEquals, []string{"global:GateComponent.Echo", "service:GateComponent.Echo", "method:GateComponent.Echo"})
+	calls = nil
+	mu.Unlock()
+
+	// The interceptor rejects the invocation with an error response
+	c.Assert(requestError(c, connector, "GateComponent.Test2", "deny"), Equals, `{"code":401,"message":"denied"}`)
+	mu.Lock()
+	c.Assert(calls, DeepEquals, []string{"global:GateComponent.Test2"})
+	mu.Unlock()
+
+	// The invocation which does not match the handler is rejected
+	c.Assert(requestError(c, connector, "GateComponent.Echo", "arg"), Equals,
+		`{"code":400,"message":"invalid argument string for GateComponent.Echo, expect *testdata.Ping"}`)
+	c.Assert(requestError(c, connector, "GateComponent.Trace", "context"), Equals,
+		`{"code":500,"message":"no context for GateComponent.Trace"}`)
+}
+
+func (s *nodeSuite) TestMasterAutoDictionary(c *C) {
+	dict := message.Dictionary()
+	message.ResetDictionary()
+	defer func() {
+		message.ResetDictionary()
+		message.SetDictionary(dict)
+	}()
+
+	gateNode := startNode(c, "127.0.0.1:14691", cluster.Options{
+		IsMaster:       true,
+		ClientAddr:     "127.0.0.1:14692",
+		AutoDictionary: true,
+	}, &GateComponent{})
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14693", cluster.Options{
+		AdvertiseAddr: "127.0.0.1:14691",
+	}, &GameComponent{})
+	defer gameNode.Shutdown()
+
+	// The master learns the routes of registered members
+	connector := dialClient(c, "127.0.0.1:14692")
+	defer connector.Close()
+	c.Assert(connector.Dictionary()["GameComponent.Test2"], Not(Equals), uint16(0))
+	c.Assert(strings.Contains(requestContent(c, connector, "GameComponent.Test2", "ping"), "game server pong2"), IsTrue)
+}
+
+func (s *nodeSuite) TestAccessControl(c *C) {
+	masterNode := startNode(c, "127.0.0.1:14671", cluster.Options{IsMaster: true}, &MasterComponent{})
+	defer masterNode.Shutdown()
+
+	gateNode := startNode(c, "127.0.0.1:14673", cluster.Options{
+		AdvertiseAddr: "127.0.0.1:14671",
+		ClientAddr:    "127.0.0.1:14672",
+		SyncKeys:      []string{component.ClaimsKey},
+	}, &GateComponent{},
+		component.WithAccess(component.Authenticated),
+		component.WithMethodAccess("Test2", component.Public),
+		component.WithMethodAccess("Grant", component.Public))
+	defer gateNode.Shutdown()
+
+	gameNode := startNode(c, "127.0.0.1:14674", cluster.Options{
+		AdvertiseAddr: "127.0.0.1:14671",
+	}, &GameComponent{}, component.WithMethodAccess("Echo", component.Roles("admin")))
+	defer gameNode.Shutdown()
+
+	connector := dialClient(c, "127.0.0.1:14672")
+	defer connector.Close()
+
+	// The unbound session can only call the public handlers
+	c.Assert(strings.Contains(requestContent(c, connector, "GateComponent.Test2", "ping"), "gate server pong2"), IsTrue)
+	c.Assert(requestError(c, connector, "GateComponent.Echo", "ping"), Equals, `{"code":401,"message":"session not authenticated"}`)
+	c.Assert(requestError(c, connector, "GameComponent.Echo", "ping"), Equals, `{"code":401,"message":"session not authenticated"}`)
+
+	// The bound session without the required roles
+	c.Assert(strings.Contains(requestContent(c, connector, "GateComponent.Grant", "user"), "granted"), IsTrue)
+	c.Assert(strings.Contains(requestContent(c, connector, "GateComponent.Echo", "ping"), "gate ping"), IsTrue)
+	c.Assert(requestError(c, connector, "GameComponent.Echo", "ping"), Equals, `{"code":403,"message":"permission denied"}`)
+
+	// The roles are synchronized to the backend session
+	c.Assert(strings.Contains(requestContent(c, connector, "GateComponent.Grant", "admin"), "granted"), IsTrue)
+	c.Assert(strings.Contains(requestContent(c, connector, "GameComponent.Echo", "ping"), "game ping"), IsTrue)
+
+	// The calls between members are not checked
+	for _, route := range []string{"GateComponent.Echo", "GameComponent.Echo"} {
+		pong := &testdata.Pong{}
+		err := gateNode.Call(context.Background(), route, &testdata.Ping{Content: "call"}, pong)
+		c.Assert(err, IsNil)
+		c.Assert(pong.Content, Equals, strings.ToLower(route[:4])+" call")
+	}
+}
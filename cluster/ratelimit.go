@@ -0,0 +1,300 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/revzim/nano/internal/log"
+)
+
+// RateLimitAction is the action applied to the messages which exceed the limit
+type RateLimitAction int
+
+const (
+	// RateLimitDrop drops the messages which exceed the limit, the dropped requests
+	// are replied with errcode.TooManyRequests
+	RateLimitDrop RateLimitAction = iota
+	// RateLimitDelay delays reading the messages of session until tokens available
+	RateLimitDelay
+	// RateLimitKick kicks the session which exceeds the limit
+	RateLimitKick
+)
+
+type (
+	// RateLimit is a token bucket limit, which allows Rate messages per second
+	// with bursts of at most Burst messages. It is unlimited if Rate is not positive.
+	RateLimit struct {
+		Rate  float64
+		Burst int
+	}
+
+	// RateLimitOptions contains the limits of the messages sent by clients, the
+	// heartbeat and handshake packets are not limited
+	RateLimitOptions struct {
+		Session RateLimit            // limit of each session
+		IP      RateLimit            // limit of all sessions from the same remote IP
+		Routes  map[string]RateLimit // limits of the routes in each session
+		Action  RateLimitAction
+		// KickReason is the payload of kick packet if Action is RateLimitKick,
+		// RateLimitedKick{Reason: "rate limited"} will be used if it is nil
+		KickReason interface{}
+	}
+
+	// RateLimitedKick is the default payload of the kick packet sent to the client
+	// which exceeds the limit
+	RateLimitedKick struct {
+		Reason string `json:"reason"`
+	}
+
+	// RateLimitStats are the counters of rate limiting
+	RateLimitStats struct {
+		Allowed uint64 // messages allowed
+		Dropped uint64 // messages dropped
+		Delayed uint64 // messages delayed
+		Kicked  uint64 // sessions kicked
+	}
+)
+
+// tokenBucket is a token bucket refilled at rate tokens per second
+type tokenBucket struct {
+	sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// take takes a token from bucket and returns the duration to wait for the token,
+// the token is taken only if it is available unless reserve is true
+func (b *tokenBucket) take(now time.Time, reserve bool) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if reserve {
+		b.tokens--
+	}
+	return wait
+}
+
+// refund returns a token taken to bucket
+func (b *tokenBucket) refund() {
+	if b == nil {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// rateLimiter limits the messages of all sessions in current node
+type rateLimiter struct {
+	sync.Mutex
+	opts  *RateLimitOptions
+	ips   map[string]*ipBucket
+	stats RateLimitStats
+}
+
+type ipBucket struct {
+	bucket *tokenBucket
+	refs   int
+}
+
+func newRateLimiter(opts *RateLimitOptions) *rateLimiter {
+	if opts == nil {
+		return nil
+	}
+	return &rateLimiter{opts: opts, ips: map[string]*ipBucket{}}
+}
+
+// sessionLimiter limits the messages of a session, it is used in the read
+// goroutine of the session only
+type sessionLimiter struct {
+	limiter *rateLimiter
+	agent   *agent
+	ip      string
+	session *tokenBucket
+	routes  map[string]*tokenBucket
+	kicked  bool
+}
+
+// acquire returns the limiter of the agent, the limiter should be released after
+// the agent closed
+func (r *rateLimiter) acquire(agent *agent) *sessionLimiter {
+	if r == nil {
+		return nil
+	}
+
+	ip := remoteIP(agent.conn.RemoteAddr())
+	r.Lock()
+	b, found := r.ips[ip]
+	if !found {
+		b = &ipBucket{bucket: newTokenBucket(r.opts.IP)}
+		r.ips[ip] = b
+	}
+	b.refs++
+	r.Unlock()
+
+	return &sessionLimiter{
+		limiter: r,
+		agent:   agent,
+		ip:      ip,
+		session: newTokenBucket(r.opts.Session),
+		routes:  map[string]*tokenBucket{},
+	}
+}
+
+func (l *sessionLimiter) release() {
+	if l == nil {
+		return
+	}
+
+	r := l.limiter
+	r.Lock()
+	defer r.Unlock()
+
+	if b := r.ips[l.ip]; b != nil {
+		b.refs--
+		if b.refs < 1 {
+			delete(r.ips, l.ip)
+		}
+	}
+}
+
+// allow decides whether the message of the route can be processed, it blocks
+// the read goroutine of session if the action is RateLimitDelay
+func (l *sessionLimiter) allow(route string) bool {
+	if l == nil {
+		return true
+	}
+	r := l.limiter
+	if l.kicked {
+		atomic.AddUint64(&r.stats.Dropped, 1)
+		return false
+	}
+
+	r.Lock()
+	ip := r.ips[l.ip].bucket
+	r.Unlock()
+
+	bucket := l.routes[route]
+	if limit, ok := r.opts.Routes[route]; ok && bucket == nil {
+		bucket = newTokenBucket(limit)
+		l.routes[route] = bucket
+	}
+
+	reserve := r.opts.Action == RateLimitDelay
+	now := time.Now()
+	var wait time.Duration
+	var taken []*tokenBucket
+	for _, b := range []*tokenBucket{ip, l.session, bucket} {
+		w := b.take(now, reserve)
+		if w > wait {
+			wait = w
+		}
+		if w <= 0 {
+			taken = append(taken, b)
+		}
+	}
+	if wait > 0 && !reserve {
+		// The dropped message does not consume the tokens of other limits
+		for _, b := range taken {
+			b.refund()
+		}
+	}
+	if wait <= 0 {
+		atomic.AddUint64(&r.stats.Allowed, 1)
+		return true
+	}
+
+	switch r.opts.Action {
+	case RateLimitDelay:
+		atomic.AddUint64(&r.stats.Delayed, 1)
+		time.Sleep(wait)
+		return true
+
+	case RateLimitKick:
+		l.kicked = true
+		atomic.AddUint64(&r.stats.Kicked, 1)
+		reason := r.opts.KickReason
+		if reason == nil {
+			reason = &RateLimitedKick{Reason: "rate limited"}
+		}
+		log.Println(fmt.Sprintf("Session rate limited and will be kicked, SessionID=%d, IP=%s", l.agent.currentSession().ID(), l.ip))
+		if err := l.agent.Kick(reason); err != nil {
+			log.Println("Kick session failed", l.agent.currentSession().ID(), err)
+		}
+		return false
+
+	default:
+		atomic.AddUint64(&r.stats.Dropped, 1)
+		return false
+	}
+}
+
+// RateLimitStats returns the counters of rate limiting in current node
+func (n *Node) RateLimitStats() RateLimitStats {
+	r := n.limiter
+	if r == nil {
+		return RateLimitStats{}
+	}
+	return RateLimitStats{
+		Allowed: atomic.LoadUint64(&r.stats.Allowed),
+		Dropped: atomic.LoadUint64(&r.stats.Dropped),
+		Delayed: atomic.LoadUint64(&r.stats.Delayed),
+		Kicked:  atomic.LoadUint64(&r.stats.Kicked),
+	}
+}
@@ -0,0 +1,95 @@
+package cluster
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(RateLimit{Rate: 10, Burst: 2})
+	now := time.Now()
+	if b.take(now, false) != 0 || b.take(now, false) != 0 {
+		t.Fatal("burst tokens should be available")
+	}
+	if wait := b.take(now, false); wait != 100*time.Millisecond {
+		t.Fatalf("expect waiting 100ms, got %v", wait)
+	}
+	// The token is refilled after 100ms
+	if wait := b.take(now.Add(100*time.Millisecond), false); wait != 0 {
+		t.Fatalf("expect token refilled, got %v", wait)
+	}
+	// The reserved token is paid by the following refilling
+	if wait := b.take(now.Add(100*time.Millisecond), true); wait != 100*time.Millisecond {
+		t.Fatalf("expect waiting 100ms, got %v", wait)
+	}
+	if wait := b.take(now.Add(100*time.Millisecond), true); wait != 200*time.Millisecond {
+		t.Fatalf("expect waiting 200ms, got %v", wait)
+	}
+
+	if newTokenBucket(RateLimit{}) != nil {
+		t.Fatal("bucket without rate should be unlimited")
+	}
+}
+
+func TestRateLimiter_Drop(t *testing.T) {
+	r := newRateLimiter(&RateLimitOptions{
+		IP:     RateLimit{Rate: 1, Burst: 3},
+		Routes: map[string]RateLimit{"Room.Chat": {Rate: 1, Burst: 1}},
+		Action: RateLimitDrop,
+	})
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	defer peer.Close()
+
+	l1 := r.acquire(&agent{conn: conn})
+	l2 := r.acquire(&agent{conn: conn})
+	if !l1.allow("Room.Chat") {
+		t.Fatal("first message should be allowed")
+	}
+	if l1.allow("Room.Chat") {
+		t.Fatal("message exceeds the route limit should be dropped")
+	}
+	// The sessions from the same IP share the limit
+	if !l1.allow("Room.Join") || !l2.allow("Room.Chat") {
+		t.Fatal("messages within the ip limit should be allowed")
+	}
+	if l2.allow("Room.Join") {
+		t.Fatal("message exceeds the ip limit should be dropped")
+	}
+
+	stats := RateLimitStats{Allowed: 3, Dropped: 2}
+	if r.stats != stats {
+		t.Fatalf("expect stats %+v, got %+v", stats, r.stats)
+	}
+
+	l1.release()
+	l2.release()
+	if len(r.ips) != 0 {
+		t.Fatalf("ip buckets should be released, got %d", len(r.ips))
+	}
+}
+
+func TestRateLimiter_Delay(t *testing.T) {
+	r := newRateLimiter(&RateLimitOptions{
+		Session: RateLimit{Rate: 20, Burst: 1},
+		Action:  RateLimitDelay,
+	})
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	defer peer.Close()
+
+	l := r.acquire(&agent{conn: conn})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if !l.allow("Room.Chat") {
+			t.Fatal("delayed message should be allowed")
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("messages should be delayed, elapsed %v", elapsed)
+	}
+	if r.stats.Delayed != 2 {
+		t.Fatalf("expect 2 messages delayed, got %d", r.stats.Delayed)
+	}
+}
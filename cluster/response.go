@@ -0,0 +1,167 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/revzim/nano/component"
+	"github.com/revzim/nano/errcode"
+	"github.com/revzim/nano/internal/log"
+	"github.com/revzim/nano/internal/message"
+	"github.com/revzim/nano/session"
+)
+
+// invoke calls the handler method with the invocation, the response is nil if
+// the handler only returns error. The invocation may be replaced by interceptors,
+// the argument which does not match the handler is rejected with errcode.BadRequest,
+// and the missing session or context with errcode.Internal.
+func invoke(handler *component.Handler, inv *component.Invocation) (interface{}, error) {
+	if inv.Session == nil {
+		return nil, errcode.Newf(errcode.Internal, "no session for %s", inv.Route)
+	}
+	arg := reflect.ValueOf(inv.Arg)
+	if !arg.IsValid() || !arg.Type().AssignableTo(handler.Type) {
+		return nil, errcode.Newf(errcode.BadRequest, "invalid argument %T for %s, expect %v", inv.Arg, inv.Route, handler.Type)
+	}
+
+	args := []reflect.Value{handler.Receiver, reflect.ValueOf(inv.Session), arg}
+	if handler.HasContext {
+		if inv.Context == nil {
+			return nil, errcode.Newf(errcode.Internal, "no context for %s", inv.Route)
+		}
+		args = []reflect.Value{handler.Receiver, reflect.ValueOf(inv.Context), reflect.ValueOf(inv.Session), arg}
+	}
+	result := handler.Method.Func.Call(args)
+
+	var err error
+	if e := result[len(result)-1].Interface(); e != nil {
+		err = e.(error)
+	}
+	if handler.HasResponse && !result[0].IsNil() {
+		return result[0].Interface(), err
+	}
+	return nil, err
+}
+
+// safeInvoke calls the invoker and recovers the panic of interceptors and handler,
+// the panic is returned as an errcode.Internal error so that the request is still
+// replied
+func safeInvoke(invoker component.Invoker, inv *component.Invocation) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println(fmt.Sprintf("Service %s panic: %+v\n%s", inv.Route, r, debug.Stack()))
+			resp, err = nil, errcode.New(errcode.Internal, "internal error")
+		}
+	}()
+	return invoker(inv)
+}
+
+// respond replies the result of handler to the request, the returned error is
+// converted into an error response, so that the client never waits for a reply
+// which will not come. The notify is never replied.
+//
+// A request is replied at most once: if the handler has already responded by
+// Session.Response, e.g. the handlers which respond manually and then return an
+// error, the returned response and error are only logged.
+func respond(s *session.Session, mid uint64, msg *message.Message, resp interface{}, err error, hasResponse bool) {
+	if err != nil {
+		log.Println(fmt.Sprintf("Service %s error: %+v", msg.Route, err))
+	}
+	if msg.Type != message.Request {
+		return
+	}
+	if (err != nil || resp != nil) && answered(s, mid) {
+		log.Println(fmt.Sprintf("Service %s has responded, the result is discarded", msg.Route))
+		return
+	}
+
+	if err != nil {
+		replyError(s, mid, msg, err, errcode.Internal)
+		return
+	}
+	if resp == nil && !hasResponse {
+		return
+	}
+
+	var v interface{} = []byte{}
+	if resp != nil {
+		v = resp
+	}
+	if err := s.ResponseMID(mid, v); err != nil {
+		log.Println(fmt.Sprintf("Respond %s failed: %v", msg.Route, err))
+	}
+}
+
+// answered reports whether the request has been responded by the handler, the
+// call entity replies once by itself
+func answered(s *session.Session, mid uint64) bool {
+	switch v := s.NetworkEntity().(type) {
+	case *agent:
+		return atomic.LoadUint64(&v.answered) == mid
+	case *acceptor:
+		return atomic.LoadUint64(&v.answered) == mid
+	default:
+		return false
+	}
+}
+
+// replyError replies the error to the request, the error which is not an
+// *errcode.Error will be replied with the code. The notify is never replied.
+func replyError(s *session.Session, mid uint64, msg *message.Message, err error, code int) {
+	if msg.Type != message.Request {
+		return
+	}
+	if err := respondError(s, mid, errcode.FromError(err, code)); err != nil {
+		log.Println(fmt.Sprintf("Respond error of %s failed: %v", msg.Route, err))
+	}
+}
+
+// respondError replies the error envelope to the request, the envelope is encoded
+// by JSON regardless of the serializer, see errcode
+func respondError(s *session.Session, mid uint64, e *errcode.Error) error {
+	if c, ok := s.NetworkEntity().(*callEntity); ok {
+		c.fail(e)
+		return nil
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return responseError(s, mid, data)
+}
+
+// responseError sends the encoded error response via the network entity of session
+func responseError(s *session.Session, mid uint64, data []byte) error {
+	switch v := s.NetworkEntity().(type) {
+	case *agent:
+		return v.responseError(mid, data)
+	case *acceptor:
+		return v.responseError(mid, data)
+	default:
+		return s.ResponseMID(mid, data)
+	}
+}
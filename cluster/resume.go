@@ -0,0 +1,217 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/revzim/nano/internal/env"
+	"github.com/revzim/nano/internal/log"
+	"github.com/revzim/nano/scheduler"
+	"github.com/revzim/nano/session"
+)
+
+// resumeTokenLen is the byte length of the random resume token
+const resumeTokenLen = 16
+
+type (
+	// parkedSession is a session whose low-level connection was broken, the session
+	// waits for the client resuming until the grace period elapsed
+	parkedSession struct {
+		session *session.Session
+		timer   *time.Timer
+		reason  session.CloseReason // why the connection was broken
+	}
+
+	// handshakeRequest is the handshake data sent by client, the client presents the
+	// resume token issued by previous handshake to resume the session, and the hash
+	// of cached route dictionary to omit the dictionary in response. The hash is
+	// accepted as dictVersion too, which is sent by pomelo clients, e.g:
+	// {"sys": {"resume": "token", "dictHash": "hash"}}
+	handshakeRequest struct {
+		Sys struct {
+			Resume      string `json:"resume"`
+			DictHash    string `json:"dictHash"`
+			DictVersion string `json:"dictVersion"`
+		} `json:"sys"`
+	}
+)
+
+// handshake resumes the session if the client presents a valid resume token and
+// returns the handshake response, a new resume token will be issued to client
+func (h *LocalHandler) handshake(agent *agent, data []byte) ([]byte, error) {
+	req := &handshakeRequest{}
+	if len(data) > 0 {
+		// the malformed handshake data is rejected by HandshakeValidator if needed
+		_ = json.Unmarshal(data, req)
+	}
+	full, compact, hash := cachedHandshake()
+	cached := hash != "" && (req.Sys.DictHash == hash || req.Sys.DictVersion == hash)
+
+	n := h.currentNode
+	if n.SessionResume <= 0 {
+		if cached {
+			return compact, nil
+		}
+		return full, nil
+	}
+
+	if req.Sys.Resume != "" {
+		if !n.resumeSession(agent, req.Sys.Resume) {
+			log.Println(fmt.Sprintf("Resume session failed with unknown token, Remote=%s", agent.conn.RemoteAddr()))
+		}
+	}
+
+	token, err := newResumeToken()
+	if err != nil {
+		return nil, err
+	}
+	agent.resumeToken = token
+
+	sys := handshakeSys(hash, !cached)
+	sys["resume"] = token
+	return handshakeResponse(sys)
+}
+
+func newResumeToken() (string, error) {
+	buf := make([]byte, resumeTokenLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// parkSession parks the session of agent until the grace period elapsed, it returns
+// false if the session cannot be resumed, e.g: the session was closed by server
+func (n *Node) parkSession(agent *agent) bool {
+	if n.SessionResume <= 0 || agent.resumeToken == "" || atomic.LoadInt32(&agent.noResume) == 1 {
+		return false
+	}
+	if n.isDraining() {
+		return false
+	}
+	select {
+	case <-env.Die:
+		return false
+	case <-n.chDie:
+		return false
+	default:
+	}
+
+	token := agent.resumeToken
+	s := agent.currentSession()
+	n.Lock()
+	n.parked[token] = &parkedSession{
+		session: s,
+		timer:   time.AfterFunc(n.SessionResume, func() { n.expireSession(token, session.CloseReasonUnknown) }),
+		reason:  agent.reason(),
+	}
+	n.Unlock()
+
+	if env.Debug {
+		log.Println(fmt.Sprintf("Session parked for resuming, SessionID=%d, UID=%d", s.ID(), s.UID()))
+	}
+	return true
+}
+
+// resumeSession reattaches the parked session to the agent, the session created
+// by the agent will be discarded
+func (n *Node) resumeSession(agent *agent, token string) bool {
+	n.Lock()
+	p, found := n.parked[token]
+	if !found {
+		n.Unlock()
+		return false
+	}
+	delete(n.parked, token)
+	p.timer.Stop()
+	p.session.Reattach(agent)
+	discarded := agent.attach(p.session)
+	delete(n.sessions, discarded.ID())
+	n.sessions[p.session.ID()] = p.session
+	n.Unlock()
+
+	scheduler.PushTask(func() { session.Lifetime.CloseWithReason(discarded, session.CloseReasonResumed) })
+
+	if env.Debug {
+		log.Println(fmt.Sprintf("Session resumed, SessionID=%d, UID=%d, Remote=%s",
+			p.session.ID(), p.session.UID(), agent.conn.RemoteAddr()))
+	}
+	return true
+}
+
+// expireSession closes the parked session whose grace period elapsed, the reason
+// of broken connection will be used if the reason is unknown
+func (n *Node) expireSession(token string, reason session.CloseReason) {
+	n.Lock()
+	p, found := n.parked[token]
+	delete(n.parked, token)
+	n.Unlock()
+
+	if !found {
+		return
+	}
+	if reason == session.CloseReasonUnknown {
+		reason = p.reason
+	}
+	n.closeSession(p.session, reason)
+}
+
+// discardParked closes the session immediately with the reason if it is parked,
+// it returns whether the session is parked
+func (n *Node) discardParked(s *session.Session, reason session.CloseReason) bool {
+	n.Lock()
+	var token string
+	for t, p := range n.parked {
+		if p.session == s {
+			token = t
+			p.timer.Stop()
+			break
+		}
+	}
+	n.Unlock()
+
+	if token == "" {
+		return false
+	}
+	n.expireSession(token, reason)
+	return true
+}
+
+// expireParked closes all parked sessions immediately
+func (n *Node) expireParked() {
+	n.Lock()
+	var tokens []string
+	for t, p := range n.parked {
+		p.timer.Stop()
+		tokens = append(tokens, t)
+	}
+	n.Unlock()
+
+	for _, t := range tokens {
+		n.expireSession(t, session.CloseReasonUnknown)
+	}
+}
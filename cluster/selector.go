@@ -0,0 +1,281 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"hash/crc32"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/session"
+)
+
+// RouteSelector selects a member to handle the messages of a service, the selected
+// member will be bound to the session router, so the following messages of the
+// session will be routed to the same member until the member left cluster.
+// The session is nil if the message is not sent by a session, e.g: Node.Call.
+type RouteSelector interface {
+	Select(s *session.Session, service string, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo
+}
+
+// RouteBinder is implemented by the RouteSelector which decides whether the selected
+// member is bound to the session router, the selected member is always bound if the
+// RouteSelector does not implement it. The member is selected again for the next
+// message if it is not bound.
+type RouteBinder interface {
+	Bindable(s *session.Session) bool
+}
+
+// RouteSelectorFunc is an adapter to allow the use of ordinary functions as RouteSelector
+type RouteSelectorFunc func(s *session.Session, service string, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo
+
+// Select implements the RouteSelector interface
+func (f RouteSelectorFunc) Select(s *session.Session, service string, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	return f(s, service, members)
+}
+
+// RandomSelector selects a member randomly, which is the default RouteSelector
+var RandomSelector RouteSelector = RouteSelectorFunc(func(_ *session.Session, _ string, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	return members[rand.Intn(len(members))]
+})
+
+// SessionKeyFunc returns the key of a session which is used to select member
+type SessionKeyFunc func(s *session.Session) string
+
+// UIDKey returns the uid of session as the key, the session id will be used if
+// the session has not bound a uid
+func UIDKey(s *session.Session) string {
+	if s == nil {
+		return ""
+	}
+	if uid := s.UID(); uid > 0 {
+		return strconv.FormatInt(uid, 10)
+	}
+	return "sid:" + strconv.FormatInt(s.ID(), 10)
+}
+
+const hashReplicas = 160
+
+type hashRing struct {
+	hashes []uint32
+	addrs  map[uint32]string
+}
+
+type consistentHashSelector struct {
+	sync.Mutex
+	key      SessionKeyFunc
+	bindable func(s *session.Session) bool
+	addrs    []string  // member addresses of current ring
+	ring     *hashRing // rebuilt when members changed
+}
+
+// NewConsistentHashSelector returns a RouteSelector which selects member by the
+// consistent hash of the session key, sessions with the same key will be routed
+// to the same member, and only a small part of sessions will be moved when members
+// join or leave. The uid of session will be used as the key if key is nil, and
+// the member selected before the uid bound is not bound to the session router,
+// so that the session is routed by its uid after binding.
+func NewConsistentHashSelector(key SessionKeyFunc) RouteSelector {
+	c := &consistentHashSelector{key: key}
+	if key == nil {
+		c.key = UIDKey
+		c.bindable = func(s *session.Session) bool {
+			return s != nil && s.UID() > 0
+		}
+	}
+	return c
+}
+
+// Bindable implements the RouteBinder interface
+func (c *consistentHashSelector) Bindable(s *session.Session) bool {
+	return c.bindable == nil || c.bindable(s)
+}
+
+// Select implements the RouteSelector interface
+func (c *consistentHashSelector) Select(s *session.Session, _ string, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	ring := c.hashRing(members)
+	hash := crc32.ChecksumIEEE([]byte(c.key(s)))
+	index := sort.Search(len(ring.hashes), func(i int) bool { return ring.hashes[i] >= hash })
+	if index == len(ring.hashes) {
+		index = 0
+	}
+	return findMember(members, ring.addrs[ring.hashes[index]])
+}
+
+func (c *consistentHashSelector) hashRing(members []*clusterpb.MemberInfo) *hashRing {
+	addrs := make([]string, 0, len(members))
+	for _, m := range members {
+		addrs = append(addrs, m.ServiceAddr)
+	}
+	sort.Strings(addrs)
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.ring != nil && sameAddrs(c.addrs, addrs) {
+		return c.ring
+	}
+
+	ring := &hashRing{addrs: map[uint32]string{}}
+	for _, addr := range addrs {
+		for i := 0; i < hashReplicas; i++ {
+			hash := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + addr))
+			ring.hashes = append(ring.hashes, hash)
+			ring.addrs[hash] = addr
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool { return ring.hashes[i] < ring.hashes[j] })
+	c.addrs = addrs
+	c.ring = ring
+	return ring
+}
+
+func sameAddrs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type roundRobinSelector struct {
+	next uint64
+}
+
+// NewRoundRobinSelector returns a RouteSelector which selects members in turn
+func NewRoundRobinSelector() RouteSelector {
+	return &roundRobinSelector{}
+}
+
+// Select implements the RouteSelector interface
+func (r *roundRobinSelector) Select(_ *session.Session, _ string, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	next := atomic.AddUint64(&r.next, 1) - 1
+	return members[next%uint64(len(members))]
+}
+
+type leastSessionsSelector struct {
+	sync.Mutex
+	counts   map[string]int              // member address to count of bindings
+	sessions map[int64]map[string]string // session id to service to member address
+}
+
+// NewLeastSessionsSelector returns a RouteSelector which selects the member bound
+// to the least sessions, the binding is released when the session closed. The
+// selector can be shared by services, a session bound to a member for two services
+// is counted twice.
+func NewLeastSessionsSelector() RouteSelector {
+	return &leastSessionsSelector{
+		counts:   map[string]int{},
+		sessions: map[int64]map[string]string{},
+	}
+}
+
+// Select implements the RouteSelector interface
+func (l *leastSessionsSelector) Select(s *session.Session, service string, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	l.Lock()
+	defer l.Unlock()
+
+	selected := members[0]
+	for _, m := range members[1:] {
+		if l.counts[m.ServiceAddr] < l.counts[selected.ServiceAddr] {
+			selected = m
+		}
+	}
+
+	if s == nil {
+		return selected
+	}
+
+	bindings, found := l.sessions[s.ID()]
+	if !found {
+		bindings = map[string]string{}
+		l.sessions[s.ID()] = bindings
+		s.Lifetime().OnClosed(l.release)
+	}
+	if addr, found := bindings[service]; found {
+		l.decrease(addr)
+	}
+	bindings[service] = selected.ServiceAddr
+	l.counts[selected.ServiceAddr]++
+	return selected
+}
+
+func (l *leastSessionsSelector) release(s *session.Session) {
+	l.Lock()
+	defer l.Unlock()
+
+	for _, addr := range l.sessions[s.ID()] {
+		l.decrease(addr)
+	}
+	delete(l.sessions, s.ID())
+}
+
+func (l *leastSessionsSelector) decrease(addr string) {
+	if l.counts[addr]--; l.counts[addr] <= 0 {
+		delete(l.counts, addr)
+	}
+}
+
+type labelSelector struct {
+	label    SessionKeyFunc
+	fallback RouteSelector
+}
+
+// NewLabelSelector returns a RouteSelector which selects among the members whose
+// label equals to the label of session, all members will be candidates if no
+// member matches. The candidate is selected by fallback, or randomly if it is nil.
+func NewLabelSelector(label SessionKeyFunc, fallback RouteSelector) RouteSelector {
+	if fallback == nil {
+		fallback = RandomSelector
+	}
+	return &labelSelector{label: label, fallback: fallback}
+}
+
+// Select implements the RouteSelector interface
+func (l *labelSelector) Select(s *session.Session, service string, members []*clusterpb.MemberInfo) *clusterpb.MemberInfo {
+	label := l.label(s)
+	var matched []*clusterpb.MemberInfo
+	for _, m := range members {
+		if m.Label == label {
+			matched = append(matched, m)
+		}
+	}
+	if len(matched) == 0 {
+		matched = members
+	}
+	return l.fallback.Select(s, service, matched)
+}
+
+// Bindable implements the RouteBinder interface
+func (l *labelSelector) Bindable(s *session.Session) bool {
+	if binder, ok := l.fallback.(RouteBinder); ok {
+		return binder.Bindable(s)
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/mock"
+	"github.com/revzim/nano/session"
+)
+
+func testMembers(n int) []*clusterpb.MemberInfo {
+	var members []*clusterpb.MemberInfo
+	for i := 0; i < n; i++ {
+		members = append(members, &clusterpb.MemberInfo{
+			Label:       fmt.Sprintf("label%d", i%2),
+			ServiceAddr: fmt.Sprintf("127.0.0.1:%d", 4500+i),
+		})
+	}
+	return members
+}
+
+func TestConsistentHashSelector(t *testing.T) {
+	selector := NewConsistentHashSelector(nil)
+	members := testMembers(4)
+
+	var sessions []*session.Session
+	selected := map[int64]string{}
+	for i := 0; i < 100; i++ {
+		s := session.New(mock.NewNetworkEntity())
+		if err := s.Bind(int64(i + 1)); err != nil {
+			t.Fatal(err)
+		}
+		sessions = append(sessions, s)
+		selected[s.ID()] = selector.Select(s, "Room", members).ServiceAddr
+	}
+
+	// Stable for the same members
+	for _, s := range sessions {
+		if addr := selector.Select(s, "Room", members).ServiceAddr; addr != selected[s.ID()] {
+			t.Fatalf("session %d moved from %s to %s", s.ID(), selected[s.ID()], addr)
+		}
+	}
+
+	// The member selected by session id is not bound to router before uid bound
+	unbound := session.New(mock.NewNetworkEntity())
+	if selector.(RouteBinder).Bindable(unbound) {
+		t.Fatal("expect unbound session not bindable")
+	}
+	if !selector.(RouteBinder).Bindable(sessions[0]) {
+		t.Fatal("expect bound session bindable")
+	}
+
+	// Only the sessions of the removed member are moved
+	removed := members[1].ServiceAddr
+	members = append(members[:1], members[2:]...)
+	for _, s := range sessions {
+		addr := selector.Select(s, "Room", members).ServiceAddr
+		if selected[s.ID()] != removed && addr != selected[s.ID()] {
+			t.Fatalf("session %d moved from %s to %s", s.ID(), selected[s.ID()], addr)
+		}
+	}
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	selector := NewRoundRobinSelector()
+	members := testMembers(3)
+	for i := 0; i < 6; i++ {
+		if m := selector.Select(nil, "Room", members); m != members[i%3] {
+			t.Fatalf("expect %s, got %s", members[i%3].ServiceAddr, m.ServiceAddr)
+		}
+	}
+}
+
+func TestLeastSessionsSelector(t *testing.T) {
+	selector := NewLeastSessionsSelector().(*leastSessionsSelector)
+	members := testMembers(2)
+
+	s1 := session.New(mock.NewNetworkEntity())
+	s2 := session.New(mock.NewNetworkEntity())
+	if m := selector.Select(s1, "Room", members); m != members[0] {
+		t.Fatalf("expect %s, got %s", members[0].ServiceAddr, m.ServiceAddr)
+	}
+	if m := selector.Select(s2, "Room", members); m != members[1] {
+		t.Fatalf("expect %s, got %s", members[1].ServiceAddr, m.ServiceAddr)
+	}
+
+	// The bindings of different services are counted separately
+	if m := selector.Select(s2, "Chat", members); m != members[0] {
+		t.Fatalf("expect %s, got %s", members[0].ServiceAddr, m.ServiceAddr)
+	}
+	if selector.counts[members[0].ServiceAddr] != 2 || selector.counts[members[1].ServiceAddr] != 1 {
+		t.Fatalf("unexpected counts: %v", selector.counts)
+	}
+
+	// The bindings are released by the listener of the closed session
+	session.Lifetime.CloseWithReason(s2, session.CloseReasonClient)
+	if selector.counts[members[0].ServiceAddr] != 1 || selector.counts[members[1].ServiceAddr] != 0 {
+		t.Fatalf("unexpected counts: %v", selector.counts)
+	}
+	s3 := session.New(mock.NewNetworkEntity())
+	if m := selector.Select(s3, "Room", members); m != members[1] {
+		t.Fatalf("expect %s, got %s", members[1].ServiceAddr, m.ServiceAddr)
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	label := "label1"
+	selector := NewLabelSelector(func(_ *session.Session) string { return label }, nil)
+	members := testMembers(4)
+	for i := 0; i < 10; i++ {
+		if m := selector.Select(nil, "Room", members); m.Label != label {
+			t.Fatalf("expect label %s, got %s", label, m.Label)
+		}
+	}
+
+	label = "unknown"
+	if m := selector.Select(nil, "Room", members); m == nil {
+		t.Fatal("expect fallback to all members")
+	}
+}
+
+func TestSelectMember_SkipDraining(t *testing.T) {
+	n := &Node{}
+	n.handler = NewHandler(n, nil)
+	members := testMembers(3)
+	members[0].Draining = true
+	members[2].Draining = true
+
+	for i := 0; i < 10; i++ {
+		if m := n.handler.selectMember(nil, "Room", members); m != members[1] {
+			t.Fatalf("expect %s, got %s", members[1].ServiceAddr, m.ServiceAddr)
+		}
+	}
+
+	// All members are draining
+	members[1].Draining = true
+	if m := n.handler.selectMember(nil, "Room", members); m == nil {
+		t.Fatal("expect a draining member selected")
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/internal/log"
+	"google.golang.org/grpc/metadata"
+)
+
+// streamAddrKey is the metadata key of the service address of the node which
+// opened the stream
+const streamAddrKey = "nano-service-addr"
+
+type streamSender interface {
+	Send(*clusterpb.StreamMessage) error
+}
+
+// memberStream is a long-lived stream opened by current node to a member, the
+// messages of all sessions sent to the member are multiplexed over it, so the
+// order of messages is preserved
+type memberStream struct {
+	sync.Mutex // serializes the Send calls
+	sender     streamSender
+	cancel     context.CancelFunc // cancels the stream
+}
+
+// inboundStream is a stream opened by a member, which is only used to receive
+// messages. There is at most one inbound stream of a member, the previous stream
+// is closed when the member opened a new one.
+type inboundStream struct {
+	cancel context.CancelFunc
+}
+
+func (s *memberStream) send(msg *clusterpb.StreamMessage) error {
+	s.Lock()
+	defer s.Unlock()
+	return s.sender.Send(msg)
+}
+
+// sendStream sends the message to the member at addr over the stream opened by
+// current node, a new stream will be opened if not found. The messages are never
+// sent over the streams opened by members, so all messages to a member keep in
+// order over a single stream.
+func (n *Node) sendStream(addr string, msg *clusterpb.StreamMessage) error {
+	stream, err := n.memberStream(addr)
+	if err != nil {
+		return err
+	}
+	if err := stream.send(msg); err != nil {
+		n.removeStream(addr, stream)
+		return err
+	}
+	return nil
+}
+
+func (n *Node) memberStream(addr string) (*memberStream, error) {
+	n.RLock()
+	stream, found := n.streams[addr]
+	n.RUnlock()
+	if found {
+		return stream, nil
+	}
+
+	pool, err := n.rpcClient.getConnPool(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stream is opened without the lock of node, which protects the session
+	// lookups, and the stream opened by other goroutine at the same time wins
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(context.Background(), streamAddrKey, n.ServiceAddr))
+	client, err := clusterpb.NewMemberClient(pool.Get()).Stream(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	n.Lock()
+	if existing, found := n.streams[addr]; found {
+		n.Unlock()
+		cancel()
+		return existing, nil
+	}
+	stream = &memberStream{sender: client, cancel: cancel}
+	n.streams[addr] = stream
+	n.Unlock()
+
+	go func() {
+		err := n.recvStream(client.Recv)
+		log.Println("Stream to member closed", addr, err)
+		n.removeStream(addr, stream)
+	}()
+	return stream, nil
+}
+
+func (n *Node) removeStream(addr string, stream *memberStream) {
+	n.Lock()
+	if n.streams[addr] == stream {
+		delete(n.streams, addr)
+	}
+	n.Unlock()
+
+	if stream.cancel != nil {
+		stream.cancel()
+	}
+}
+
+// closeStreams closes all streams opened by current node
+func (n *Node) closeStreams() {
+	n.Lock()
+	streams := n.streams
+	n.streams = map[string]*memberStream{}
+	n.Unlock()
+
+	for _, stream := range streams {
+		if stream.cancel != nil {
+			stream.cancel()
+		}
+	}
+}
+
+// Stream implements the MemberServer interface, the messages sent by the member
+// which opened the stream are received from it
+func (n *Node) Stream(server clusterpb.Member_StreamServer) error {
+	md, _ := metadata.FromIncomingContext(server.Context())
+	addrs := md.Get(streamAddrKey)
+	if len(addrs) != 1 {
+		return errors.New("service address not found in stream metadata")
+	}
+	addr := addrs[0]
+	if _, _, err := net.SplitHostPort(addr); err != nil || addr == n.ServiceAddr {
+		return fmt.Errorf("invalid service address %q in stream metadata", addr)
+	}
+
+	ctx, cancel := context.WithCancel(server.Context())
+	defer cancel()
+	stream := &inboundStream{cancel: cancel}
+	n.Lock()
+	if prev, found := n.inbound[addr]; found {
+		// The member reopened the stream, e.g: the previous one was broken
+		prev.cancel()
+	}
+	n.inbound[addr] = stream
+	n.Unlock()
+	defer func() {
+		n.Lock()
+		if n.inbound[addr] == stream {
+			delete(n.inbound, addr)
+		}
+		n.Unlock()
+	}()
+
+	chErr := make(chan error, 1)
+	go func() { chErr <- n.recvStream(server.Recv) }()
+
+	select {
+	case err := <-chErr:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-n.chDie:
+		return nil
+	}
+}
+
+// recvStream handles the messages received from stream until the stream closed
+func (n *Node) recvStream(recv func() (*clusterpb.StreamMessage, error)) error {
+	for {
+		msg, err := recv()
+		if err != nil {
+			return err
+		}
+		if err := n.handleStreamMessage(msg); err != nil {
+			log.Println("Process stream message error", err)
+		}
+	}
+}
+
+func (n *Node) handleStreamMessage(msg *clusterpb.StreamMessage) error {
+	ctx := context.Background()
+	var err error
+	switch m := msg.Message.(type) {
+	case *clusterpb.StreamMessage_Request:
+		_, err = n.HandleRequest(ctx, m.Request)
+	case *clusterpb.StreamMessage_Notify:
+		_, err = n.HandleNotify(ctx, m.Notify)
+	case *clusterpb.StreamMessage_Push:
+		_, err = n.HandlePush(ctx, m.Push)
+	case *clusterpb.StreamMessage_Response:
+		_, err = n.HandleResponse(ctx, m.Response)
+	case *clusterpb.StreamMessage_SessionClosed:
+		_, err = n.SessionClosed(ctx, m.SessionClosed)
+	case *clusterpb.StreamMessage_CloseSession:
+		_, err = n.CloseSession(ctx, m.CloseSession)
+	case *clusterpb.StreamMessage_SessionBound:
+		_, err = n.SessionBound(ctx, m.SessionBound)
+	case *clusterpb.StreamMessage_Multicast:
+		_, err = n.HandleMulticast(ctx, m.Multicast)
+	case *clusterpb.StreamMessage_KickSession:
+		_, err = n.KickSession(ctx, m.KickSession)
+	case *clusterpb.StreamMessage_Sync:
+		_, err = n.HandleSync(ctx, m.Sync)
+	default:
+		err = fmt.Errorf("unknown stream message: %T", msg.Message)
+	}
+	return err
+}
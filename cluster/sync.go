@@ -0,0 +1,195 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/revzim/nano/cluster/clusterpb"
+	"github.com/revzim/nano/session"
+)
+
+// encodeState encodes the session data by gob, the types of values except the
+// builtin types should be registered by gob.Register
+func encodeState(data map[string]interface{}) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, nil
+	}
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeState(state []byte) (map[string]interface{}, error) {
+	if len(state) < 1 {
+		return nil, nil
+	}
+	data := map[string]interface{}{}
+	if err := gob.NewDecoder(bytes.NewReader(state)).Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// syncedState is the state of synced keys carried in the messages forwarded from
+// gate, the keys absent in data have been removed from the session of gate
+type syncedState struct {
+	Keys []string
+	Data map[string]interface{}
+}
+
+// syncState returns the encoded state of synced keys in session
+func (n *Node) syncState(s *session.Session) ([]byte, error) {
+	if len(n.SyncKeys) < 1 {
+		return nil, nil
+	}
+	st := &syncedState{Keys: n.SyncKeys, Data: map[string]interface{}{}}
+	for _, key := range n.SyncKeys {
+		if s.HasKey(key) {
+			st.Data[key] = s.Value(key)
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(st); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// synchronize updates the backend session by the uid and state carried in the
+// message forwarded from gate, the synced keys absent in the state are removed.
+// The uid is ignored if the message was forwarded before the gate saw the uid
+// changed by members, see acceptor.synchronize.
+func (n *Node) synchronize(s *session.Session, uid int64, version uint64, state []byte) error {
+	st := &syncedState{}
+	if len(state) > 0 {
+		if err := gob.NewDecoder(bytes.NewReader(state)).Decode(st); err != nil {
+			return err
+		}
+	}
+	if a, ok := s.NetworkEntity().(*acceptor); ok {
+		a.synchronize(uid, version, st.Data)
+	} else {
+		s.Synchronize(uid, st.Data)
+	}
+	for _, key := range st.Keys {
+		if _, found := st.Data[key]; !found && s.HasKey(key) {
+			s.Remove(key)
+		}
+	}
+	return nil
+}
+
+// bindVersion returns the count of uid changes of the gate session, which is
+// carried with the uid in the forwarded messages. It should be read before the
+// uid, and it is increased after the uid changed, so that the uid read is not
+// older than the version.
+func bindVersion(s *session.Session) uint64 {
+	switch v := s.NetworkEntity().(type) {
+	case *agent:
+		return atomic.LoadUint64(&v.rebinds)
+	case *acceptor:
+		v.bindMu.Lock()
+		defer v.bindMu.Unlock()
+		return v.bindVersion
+	default:
+		return 0
+	}
+}
+
+// rebound increases the bind version after the uid of gate session changed
+func (a *agent) rebound() {
+	atomic.AddUint64(&a.rebinds, 1)
+}
+
+// rebind marks the uid of backend session changed by members, the uid carried in
+// the messages forwarded before the gate saw the change will be ignored. The uid
+// is changed by fn if it is not nil.
+func (a *acceptor) rebind(fn func()) {
+	a.bindMu.Lock()
+	defer a.bindMu.Unlock()
+
+	a.bindPending = true
+	if fn != nil {
+		fn()
+	}
+}
+
+// synchronize updates the uid and data forwarded from gate with the bind version,
+// the uid is ignored if the gate has not seen the uid changed by members, which
+// is announced to the gate asynchronously
+func (a *acceptor) synchronize(uid int64, version uint64, data map[string]interface{}) {
+	a.bindMu.Lock()
+	defer a.bindMu.Unlock()
+
+	if a.bindPending && version <= a.bindVersion {
+		for key, value := range data {
+			a.session.Set(key, value)
+		}
+		return
+	}
+	a.bindPending = false
+	if version > a.bindVersion {
+		a.bindVersion = version
+	}
+	a.session.Synchronize(uid, data)
+}
+
+// Sync implements the session.Syncer interface
+func (a *acceptor) Sync(data map[string]interface{}) error {
+	state, err := encodeState(data)
+	if err != nil || state == nil {
+		return err
+	}
+	request := &clusterpb.SyncMessage{
+		SessionId: a.sid,
+		State:     state,
+	}
+	if a.node.StreamTransport {
+		return a.node.sendStream(a.gateAddr, &clusterpb.StreamMessage{
+			Message: &clusterpb.StreamMessage_Sync{Sync: request},
+		})
+	}
+	_, err = a.gateClient.HandleSync(context.Background(), request)
+	return err
+}
+
+// HandleSync implements the MemberServer interface
+func (n *Node) HandleSync(_ context.Context, req *clusterpb.SyncMessage) (*clusterpb.MemberHandleResponse, error) {
+	s := n.findSession(req.SessionId)
+	if s == nil {
+		return &clusterpb.MemberHandleResponse{}, fmt.Errorf("session not found: %v", req.SessionId)
+	}
+	data, err := decodeState(req.State)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range data {
+		s.Set(key, value)
+	}
+	return &clusterpb.MemberHandleResponse{}, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package component
+
+import (
+	"encoding/gob"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/revzim/nano/errcode"
+	"github.com/revzim/nano/session"
+)
+
+// The session data keys used to look up the roles of session, add them to the
+// SyncKeys of gate to check the roles on backend nodes
+const (
+	// RolesKey is the key of roles, the value can be []string, []interface{}
+	// or a comma separated string
+	RolesKey = "roles"
+
+	// ClaimsKey is the key of JWT claims, the roles are looked up by the
+	// RolesKey of claims if the session has no RolesKey
+	ClaimsKey = "claims"
+)
+
+// The session data is encoded by gob when synchronized in cluster, so that
+// the types of claims and roles should be registered
+func init() {
+	gob.Register(jwt.MapClaims{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// Access represents the access control of handler, which is checked before
+// the handler invoked by clients. The handlers called by cluster.Node.Call are
+// not checked, the members of cluster are trusted.
+type Access struct {
+	Authenticated bool     // whether the session must be bound to a UID
+	Roles         []string // the session must have one of the roles, implies Authenticated
+}
+
+var (
+	// Public handlers can be called by all sessions
+	Public = Access{}
+
+	// Authenticated handlers can be called by the sessions bound to a UID
+	Authenticated = Access{Authenticated: true}
+)
+
+// Roles returns the Access which requires one of the roles
+func Roles(roles ...string) Access {
+	return Access{Authenticated: true, Roles: roles}
+}
+
+// IsPublic returns whether the handler can be called by all sessions
+func (a Access) IsPublic() bool {
+	return !a.Authenticated && len(a.Roles) == 0
+}
+
+// Check returns an errcode.Error if the session is not allowed to call the
+// handler, Unauthorized for the unbound session and Forbidden for the session
+// without the required roles
+func (a Access) Check(s *session.Session) error {
+	if a.IsPublic() {
+		return nil
+	}
+	if s.UID() == 0 {
+		return errcode.New(errcode.Unauthorized, "session not authenticated")
+	}
+	if len(a.Roles) == 0 {
+		return nil
+	}
+	for _, role := range SessionRoles(s) {
+		for _, r := range a.Roles {
+			if role == r {
+				return nil
+			}
+		}
+	}
+	return errcode.New(errcode.Forbidden, "permission denied")
+}
+
+// SessionRoles returns the roles stored in the session data, see RolesKey
+// and ClaimsKey
+func SessionRoles(s *session.Session) []string {
+	if s.HasKey(RolesKey) {
+		return toRoles(s.Value(RolesKey))
+	}
+
+	switch claims := s.Value(ClaimsKey).(type) {
+	case jwt.MapClaims:
+		return toRoles(claims[RolesKey])
+	case map[string]interface{}:
+		return toRoles(claims[RolesKey])
+	}
+	return nil
+}
+
+func toRoles(v interface{}) []string {
+	switch roles := v.(type) {
+	case []string:
+		return roles
+	case []interface{}:
+		var rs []string
+		for _, r := range roles {
+			if role, ok := r.(string); ok {
+				rs = append(rs, role)
+			}
+		}
+		return rs
+	case string:
+		var rs []string
+		for _, r := range strings.Split(roles, ",") {
+			if role := strings.TrimSpace(r); role != "" {
+				rs = append(rs, role)
+			}
+		}
+		return rs
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package component
+
+import (
+	"context"
+
+	"github.com/revzim/nano/session"
+)
+
+type (
+	// Invocation represents a call of handler, which is passed to interceptors
+	Invocation struct {
+		Route   string           // route of the handler, e.g: Room.Join
+		Context context.Context  // context of the request
+		Session *session.Session // session of the request
+		Arg     interface{}      // decoded argument, []byte if the handler accepts raw data
+	}
+
+	// Invoker invokes the next interceptor or the handler, it returns the response
+	// and error of handler. The response is nil if the handler only returns error.
+	Invoker func(inv *Invocation) (interface{}, error)
+
+	// Interceptor intercepts the handler invocation, it can inspect or replace the
+	// argument, response and error, or return without calling next to reject the
+	// invocation, e.g: authentication, validation, metrics and recovering panics.
+	// The returned error is replied to the request, see errcode.Error.
+	Interceptor func(inv *Invocation, next Invoker) (interface{}, error)
+)
+
+// Chain returns an Invoker which calls the interceptors in order and the invoker
+// at last
+func Chain(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(inv *Invocation) (interface{}, error) {
+			return interceptor(inv, next)
+		}
+	}
+	return invoker
+}
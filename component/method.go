@@ -21,6 +21,7 @@
 package component
 
 import (
+	"context"
 	"reflect"
 	"unicode"
 	"unicode/utf8"
@@ -29,6 +30,7 @@ import (
 )
 
 var (
+	typeOfContext = reflect.TypeOf((*context.Context)(nil)).Elem()
 	typeOfError   = reflect.TypeOf((*error)(nil)).Elem()
 	typeOfBytes   = reflect.TypeOf(([]byte)(nil))
 	typeOfSession = reflect.TypeOf(session.New(nil))
@@ -56,21 +58,37 @@ func isHandlerMethod(method reflect.Method) bool {
 		return false
 	}
 
-	// Method needs three ins: receiver, *Session, []byte or pointer.
-	if mt.NumIn() != 3 {
+	// Method needs three ins: receiver, *Session, []byte or pointer, or four ins
+	// with a context.Context before *Session.
+	if mt.NumIn() != 3 && mt.NumIn() != 4 {
+		return false
+	}
+	in := 1
+	if mt.NumIn() == 4 {
+		if mt.In(1) != typeOfContext {
+			return false
+		}
+		in = 2
+	}
+
+	// Method needs one outs: error, or two outs: []byte or pointer, error
+	if mt.NumOut() != 1 && mt.NumOut() != 2 {
+		return false
+	}
+
+	if t1 := mt.In(in); t1.Kind() != reflect.Ptr || t1 != typeOfSession {
 		return false
 	}
 
-	// Method needs one outs: error
-	if mt.NumOut() != 1 {
+	if mt.In(in+1).Kind() != reflect.Ptr && mt.In(in+1) != typeOfBytes {
 		return false
 	}
 
-	if t1 := mt.In(1); t1.Kind() != reflect.Ptr || t1 != typeOfSession {
+	if mt.NumOut() == 2 && mt.Out(0).Kind() != reflect.Ptr && mt.Out(0) != typeOfBytes {
 		return false
 	}
 
-	if (mt.In(2).Kind() != reflect.Ptr && mt.In(2) != typeOfBytes) || mt.Out(0) != typeOfError {
+	if mt.Out(mt.NumOut()-1) != typeOfError {
 		return false
 	}
 	return true
@@ -25,6 +25,12 @@ type (
 		name      string              // component name
 		nameFunc  func(string) string // rename handler name
 		schedName string              // schedName name
+
+		interceptors       []Interceptor            // interceptors of all handlers
+		methodInterceptors map[string][]Interceptor // interceptors of the specified handlers
+
+		access       *Access           // access control of all handlers
+		methodAccess map[string]Access // access control of the specified handlers
 	}
 
 	// Option used to customize handler
@@ -52,3 +58,43 @@ func WithSchedulerName(name string) Option {
 		opt.schedName = name
 	}
 }
+
+// WithInterceptors adds the interceptors to all handlers of the component, which
+// are called after the global interceptors
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(opt *options) {
+		opt.interceptors = append(opt.interceptors, interceptors...)
+	}
+}
+
+// WithMethodInterceptors adds the interceptors to the handler, which are called
+// after the interceptors of component. The name is the handler name in route,
+// e.g: Join of Room.Join
+func WithMethodInterceptors(name string, interceptors ...Interceptor) Option {
+	return func(opt *options) {
+		if opt.methodInterceptors == nil {
+			opt.methodInterceptors = map[string][]Interceptor{}
+		}
+		opt.methodInterceptors[name] = append(opt.methodInterceptors[name], interceptors...)
+	}
+}
+
+// WithAccess sets the access control of all handlers of the component, the
+// handlers are public by default
+func WithAccess(access Access) Option {
+	return func(opt *options) {
+		opt.access = &access
+	}
+}
+
+// WithMethodAccess sets the access control of the handler, which overrides the
+// access control of component. The name is the handler name in route, e.g:
+// Join of Room.Join
+func WithMethodAccess(name string, access Access) Option {
+	return func(opt *options) {
+		if opt.methodAccess == nil {
+			opt.methodAccess = map[string]Access{}
+		}
+		opt.methodAccess[name] = access
+	}
+}
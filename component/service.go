@@ -28,10 +28,18 @@ import (
 type (
 	//Handler represents a message.Message's handler's meta information.
 	Handler struct {
-		Receiver reflect.Value  // receiver of method
-		Method   reflect.Method // method stub
-		Type     reflect.Type   // arg type of method
-		IsRawArg bool           // whether the data need to unserialize
+		Receiver    reflect.Value  // receiver of method
+		Method      reflect.Method // method stub
+		Type        reflect.Type   // arg type of method
+		IsRawArg    bool           // whether the data need to unserialize
+		HasContext  bool           // whether the method accepts a context.Context
+		HasResponse bool           // whether the method returns the response
+
+		// Interceptors are the interceptors of the service and the method
+		Interceptors []Interceptor
+
+		// Access is the access control checked before the handler invoked
+		Access Access
 	}
 
 	// Service implements a specific service, some of it's methods will be
@@ -75,15 +83,22 @@ func (s *Service) suitableHandlerMethods(typ reflect.Type) map[string]*Handler {
 		mt := method.Type
 		mn := method.Name
 		if isHandlerMethod(method) {
+			arg := mt.In(mt.NumIn() - 1)
 			raw := false
-			if mt.In(2) == typeOfBytes {
+			if arg == typeOfBytes {
 				raw = true
 			}
 			// rewrite handler name
 			if s.Options.nameFunc != nil {
 				mn = s.Options.nameFunc(mn)
 			}
-			methods[mn] = &Handler{Method: method, Type: mt.In(2), IsRawArg: raw}
+			methods[mn] = &Handler{
+				Method:      method,
+				Type:        arg,
+				IsRawArg:    raw,
+				HasContext:  mt.NumIn() == 4,
+				HasResponse: mt.NumOut() == 2,
+			}
 		}
 	}
 	return methods
@@ -95,6 +110,8 @@ func (s *Service) suitableHandlerMethods(typ reflect.Type) map[string]*Handler {
 // - two arguments, both of exported type
 // - the first argument is *session.Session
 // - the second argument is []byte or a pointer
+// - optionally a context.Context argument before *session.Session
+// - returns error, or the response([]byte or a pointer) and error
 func (s *Service) ExtractHandler() error {
 	typeName := reflect.Indirect(s.Receiver).Type().Name()
 	if typeName == "" {
@@ -119,8 +136,19 @@ func (s *Service) ExtractHandler() error {
 		return errors.New(str)
 	}
 
-	for i := range s.Handlers {
-		s.Handlers[i].Receiver = s.Receiver
+	for name, handler := range s.Handlers {
+		handler.Receiver = s.Receiver
+
+		var interceptors []Interceptor
+		interceptors = append(interceptors, s.Options.interceptors...)
+		interceptors = append(interceptors, s.Options.methodInterceptors[name]...)
+		handler.Interceptors = interceptors
+
+		if access, found := s.Options.methodAccess[name]; found {
+			handler.Access = access
+		} else if s.Options.access != nil {
+			handler.Access = *s.Options.access
+		}
 	}
 
 	return nil
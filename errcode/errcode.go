@@ -0,0 +1,83 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package errcode provides the error with a code, which is replied to clients
+// as the error response envelope, e.g:
+// {"code": 404, "message": "room not found", "details": {"room": 1}}
+// The envelope is always encoded by JSON regardless of the serializer, the clients
+// should decode the response with the error flag as JSON even if they use protobuf
+// for other messages. The handshake response announces it by sys.errorEncoding.
+package errcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The codes used by nano, the application can define its own codes
+const (
+	BadRequest       = 400 // the request cannot be deserialized
+	Unauthorized     = 401 // the session is not authenticated
+	Forbidden        = 403 // the request is rejected, e.g: by pipeline
+	NotFound         = 404 // the route is not found
+	TooManyRequests  = 429 // the request is dropped by rate limiting
+	Internal         = 500 // the handler failed
+	Unavailable      = 503 // the member serving the route is unavailable
+	DeadlineExceeded = 504 // the deadline of request exceeded
+)
+
+// Error represents an error with a code, the handler can return it to choose
+// the code of error response
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// New returns an Error with the code and message
+func New(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Newf returns an Error with the code and formatted message
+func Newf(code int, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WithDetails returns a copy of the error with the details, which should be
+// able to be encoded by JSON
+func (e *Error) WithDetails(details interface{}) *Error {
+	return &Error{Code: e.Code, Message: e.Message, Details: details}
+}
+
+// FromError returns the Error in the chain of err, or wraps err as an Error
+// with the code if not found
+func FromError(err error, code int) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Code: code, Message: err.Error()}
+}
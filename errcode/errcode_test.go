@@ -0,0 +1,38 @@
+package errcode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFromError(t *testing.T) {
+	e := New(NotFound, "room not found")
+	if got := FromError(fmt.Errorf("join: %w", e), Internal); got != e {
+		t.Fatalf("expect %v, got %v", e, got)
+	}
+
+	got := FromError(errors.New("failed"), Internal)
+	if got.Code != Internal || got.Message != "failed" {
+		t.Fatalf("unexpected error %+v", got)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	data, err := json.Marshal(Newf(BadRequest, "bad %s", "name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"code":400,"message":"bad name"}` {
+		t.Fatalf("unexpected envelope %s", data)
+	}
+
+	data, err = json.Marshal(New(NotFound, "room not found").WithDetails(map[string]int{"room": 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"code":404,"message":"room not found","details":{"room":1}}` {
+		t.Fatalf("unexpected envelope %s", data)
+	}
+}
@@ -65,7 +65,7 @@ func InitPomeloClient(addr string) {
 }
 
 func handleClose() {
-	sigChan := make(chan os.Signal)
+	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan
@@ -27,6 +27,7 @@ import (
 	"sync/atomic"
 
 	"github.com/revzim/azdrivers"
+	"github.com/revzim/nano/cluster"
 	"github.com/revzim/nano/internal/env"
 	"github.com/revzim/nano/internal/log"
 	"github.com/revzim/nano/internal/message"
@@ -218,17 +219,16 @@ func (g *Group) Multicast(route string, v interface{}, filter SessionFilter) err
 	}
 
 	g.RLock()
-	defer g.RUnlock()
-
+	var sessions []*session.Session
 	for _, s := range g.sessions {
-		if !filter(s) {
-			continue
-		}
-		if err = s.Push(route, data); err != nil {
-			log.Println(err.Error())
+		if filter(s) {
+			sessions = append(sessions, s)
 		}
 	}
+	g.RUnlock()
 
+	// The sessions live in the same remote gate are pushed by one batched RPC
+	cluster.Multicast(sessions, route, data)
 	return nil
 }
 
@@ -248,15 +248,14 @@ func (g *Group) Broadcast(route string, v interface{}) error {
 	}
 
 	g.RLock()
-	defer g.RUnlock()
-
+	sessions := make([]*session.Session, 0, len(g.sessions))
 	for _, s := range g.sessions {
-		if err = s.Push(route, data); err != nil {
-			log.Println(fmt.Sprintf("Session push message error, ID=%d, UID=%d, Error=%s", s.ID(), s.UID(), err.Error()))
-		}
+		sessions = append(sessions, s)
 	}
+	g.RUnlock()
 
-	return err
+	// The sessions live in the same remote gate are pushed by one batched RPC
+	return cluster.Multicast(sessions, route, data)
 }
 
 // Contains check whether a UID is contained in current group or not
@@ -21,7 +21,10 @@
 package nano
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -34,12 +37,16 @@ import (
 	"github.com/revzim/nano/component"
 	"github.com/revzim/nano/internal/env"
 	"github.com/revzim/nano/internal/log"
+	"github.com/revzim/nano/internal/message"
 	"github.com/revzim/nano/internal/runtime"
 	"github.com/revzim/nano/scheduler"
 )
 
 var running int32
 
+// chDrain receives the drain request
+var chDrain = make(chan struct{}, 1)
+
 // VERSION returns current nano version
 var VERSION = "0.6.0"
 
@@ -71,15 +78,18 @@ func Listen(addr string, opts ...Option) {
 		env.Wd, _ = filepath.Abs(wd)
 	}
 
+	// The member heartbeat interval is 5 secondes by default, which can be
+	// disabled by WithMemberHeartbeat(0)
 	opt := cluster.Options{
-		Components: &component.Components{},
+		Components:      &component.Components{},
+		MemberHeartbeat: time.Second * 5,
 	}
 	for _, option := range opts {
 		option(&opt)
 	}
 
 	// Use listen address as client address in non-cluster mode
-	if !opt.IsMaster && opt.AdvertiseAddr == "" && opt.ClientAddr == "" {
+	if !opt.IsMaster && opt.AdvertiseAddr == "" && opt.ClientAddr == "" && opt.Discovery == nil {
 		log.Println("The current server running in singleton mode")
 		opt.ClientAddr = addr
 	}
@@ -108,14 +118,35 @@ func Listen(addr string, opts ...Option) {
 	}
 
 	go scheduler.Sched()
-	sg := make(chan os.Signal)
+	sg := make(chan os.Signal, 1)
 	signal.Notify(sg, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGKILL, syscall.SIGTERM)
 
+	var drain bool
 	select {
 	case <-env.Die:
 		log.Println("The app will shutdown in a few seconds")
 	case s := <-sg:
 		log.Println("Nano server got signal", s)
+		drain = true
+	case <-chDrain:
+		drain = true
+	}
+
+	// Drain the current node before shutdown, a signal received while
+	// draining will shutdown the server immediately
+	if drain && node.DrainTimeout > 0 {
+		log.Println("Nano server is draining...")
+		chDrained := make(chan struct{})
+		go func() {
+			node.Drain(node.DrainTimeout)
+			close(chDrained)
+		}()
+		select {
+		case <-chDrained:
+		case s := <-sg:
+			log.Println("Nano server got signal while draining", s)
+		case <-env.Die:
+		}
 	}
 
 	log.Println("Nano server is stopping...")
@@ -130,3 +161,66 @@ func Listen(addr string, opts ...Option) {
 func Shutdown() {
 	close(env.Die)
 }
+
+// Drain send a signal to let 'nano' drain the current node and then shutdown
+// itself, see cluster.Node.Drain. The node is shutdown without draining unless
+// the drain timeout is set by WithDrainTimeout.
+func Drain() {
+	select {
+	case chDrain <- struct{}{}:
+	default:
+	}
+}
+
+// Call calls the handler of route in cluster and waits for its response, the
+// response will be deserialized to resp. It returns a *cluster.RemoteError if
+// the remote handler failed.
+// Pass the context.Context of handler when calling from a handler, see
+// cluster.Node.Call.
+func Call(ctx context.Context, route string, req, resp interface{}) error {
+	node := runtime.CurrentNode
+	if node == nil {
+		return ErrNotRunning
+	}
+	return node.Call(ctx, route, req, resp)
+}
+
+// PushToUID pushes the message to the client whose session bound to the uid,
+// the session can live in any gate of cluster
+func PushToUID(uid int64, route string, v interface{}) error {
+	node := runtime.CurrentNode
+	if node == nil {
+		return ErrNotRunning
+	}
+	return node.PushToUID(uid, route, v)
+}
+
+// KickUID kicks the session bound to the uid with the reason, the session can
+// live in any gate of cluster
+func KickUID(uid int64, reason interface{}) error {
+	node := runtime.CurrentNode
+	if node == nil {
+		return ErrNotRunning
+	}
+	return node.KickUID(uid, reason)
+}
+
+// RateLimitStats returns the counters of rate limiting in current node
+func RateLimitStats() (cluster.RateLimitStats, error) {
+	node := runtime.CurrentNode
+	if node == nil {
+		return cluster.RateLimitStats{}, ErrNotRunning
+	}
+	return node.RateLimitStats(), nil
+}
+
+// ExportDictionary writes the route dictionary to the file as JSON, e.g:
+// {"Room.Join": 1}, which can be bundled by clients. The dictionary generated
+// by WithAutoDictionary is completed after the cluster members registered.
+func ExportDictionary(path string) error {
+	data, err := json.MarshalIndent(message.Dictionary(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
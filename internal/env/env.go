@@ -41,6 +41,7 @@ var (
 	Debug              bool                     // enable Debug
 	WSPath             string                   // WebSocket path(eg: ws://127.0.0.1/WSPath)
 	HandshakeValidator func([]byte) error       // When you need to verify the custom data of the handshake request
+	HandshakeUserData  interface{}              // The custom data sent to client in the handshake response
 
 	// timerPrecision indicates the precision of timer, default is time.Second
 	TimerPrecision = time.Second
@@ -51,7 +52,9 @@ var (
 
 	Serializer serialize.Serializer
 
-	GrpcOptions = []grpc.DialOption{grpc.WithInsecure()}
+	// GrpcOptions are the extra options to dial cluster members, the transport
+	// credentials are insecure unless set by these options or the cluster TLS config
+	GrpcOptions []grpc.DialOption
 
 	JWT *auth.JWT
 
@@ -21,10 +21,16 @@
 package message
 
 import (
+	"crypto/md5"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/revzim/nano/internal/log"
 )
@@ -43,6 +49,7 @@ const (
 const (
 	msgRouteCompressMask = 0x01
 	msgTypeMask          = 0x07
+	msgErrorMask         = 0x20
 	msgRouteLengthMask   = 0xFF
 	msgHeadLength        = 0x02
 )
@@ -59,6 +66,7 @@ func (t Type) String() string {
 }
 
 var (
+	mu     sync.RWMutex              // protects the dictionary
 	routes = make(map[string]uint16) // route map to code
 	codes  = make(map[uint16]string) // code map to route
 )
@@ -69,6 +77,7 @@ var (
 	ErrInvalidMessage    = errors.New("invalid message")
 	ErrRouteInfoNotFound = errors.New("route info not found in dictionary")
 	ErrWrongMessage      = errors.New("wrong message")
+	ErrDictionaryFull    = errors.New("no available code in dictionary")
 )
 
 // Message represents a unmarshaled message or a message which to be marshaled
@@ -77,6 +86,7 @@ type Message struct {
 	ID         uint64 // unique id, zero while notify mode
 	Route      string // route for locating service
 	Data       []byte // payload
+	Error      bool   // whether the response is an error
 	compressed bool   // is message compressed
 }
 
@@ -115,6 +125,7 @@ func invalidType(t Type) bool {
 // | response |----010-|<message id>        |
 // | push     |----011-|<route>             |
 // ------------------------------------------
+// The 6th bit of flag field indicates the response is an error, e.g: --1-010-.
 // The figure above indicates that the bit does not affect the type of message.
 // See ref: https://github.com/lonnng/nano/blob/master/docs/communication_protocol.md
 func Encode(m *Message) ([]byte, error) {
@@ -124,8 +135,13 @@ func Encode(m *Message) ([]byte, error) {
 
 	buf := make([]byte, 0)
 	flag := byte(m.Type) << 1
+	if m.Error {
+		flag |= msgErrorMask
+	}
 
+	mu.RLock()
 	code, compressed := routes[m.Route]
+	mu.RUnlock()
 	if compressed {
 		flag |= msgRouteCompressMask
 	}
@@ -170,6 +186,7 @@ func Decode(data []byte) (*Message, error) {
 	flag := data[0]
 	offset := 1
 	m.Type = Type((flag >> 1) & msgTypeMask)
+	m.Error = flag&msgErrorMask == msgErrorMask
 
 	if invalidType(m.Type) {
 		return nil, ErrWrongMessageType
@@ -199,7 +216,9 @@ func Decode(data []byte) (*Message, error) {
 		if flag&msgRouteCompressMask == 1 {
 			m.compressed = true
 			code := binary.BigEndian.Uint16(data[offset:(offset + 2)])
+			mu.RLock()
 			route, ok := codes[code]
+			mu.RUnlock()
 			if !ok {
 				return nil, ErrRouteInfoNotFound
 			}
@@ -224,23 +243,124 @@ func Decode(data []byte) (*Message, error) {
 	return m, nil
 }
 
-// SetDictionary set routes map which be used to compress route.
+// SetDictionary set routes map which be used to compress route, the dictionary
+// will be rejected if a route or code has been bound to another one.
 // TODO(warning): set dictionary in runtime would be a dangerous operation!!!!!!
-func SetDictionary(dict map[string]uint16) {
+func SetDictionary(dict map[string]uint16) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	// duplication check
+	seen := make(map[uint16]string, len(dict))
 	for route, code := range dict {
 		r := strings.TrimSpace(route)
-
-		// duplication check
-		if _, ok := routes[r]; ok {
-			log.Println(fmt.Sprintf("duplicated route(route: %s, code: %d)", r, code))
+		if c, ok := routes[r]; ok && c != code {
+			return fmt.Errorf("duplicated route(route: %s, code: %d, bound code: %d)", r, code, c)
 		}
-
-		if _, ok := codes[code]; ok {
-			log.Println(fmt.Sprintf("duplicated route(route: %s, code: %d)", r, code))
+		if b, ok := codes[code]; ok && b != r {
+			return fmt.Errorf("duplicated code(route: %s, code: %d, bound route: %s)", r, code, b)
+		}
+		if b, ok := seen[code]; ok && b != r {
+			return fmt.Errorf("duplicated code(route: %s, code: %d, bound route: %s)", r, code, b)
 		}
+		seen[code] = r
+	}
 
-		// update map, using last value when key duplicated
+	for route, code := range dict {
+		r := strings.TrimSpace(route)
 		routes[r] = code
 		codes[code] = r
 	}
+	return nil
+}
+
+// AddRoutes generates codes for the routes which are not in dictionary. The code
+// is derived from the hash of route, so that it is stable across nodes and
+// restarts, the routes added together are processed by lexical order to keep it
+// deterministic. A code is never changed once assigned, because the clients may
+// have received it: if the code has been bound to another route, only the new
+// route takes the next free code. It returns the number of routes added.
+func AddRoutes(rs []string) (int, error) {
+	sorted := make([]string, 0, len(rs))
+	for _, route := range rs {
+		sorted = append(sorted, strings.TrimSpace(route))
+	}
+	sort.Strings(sorted)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	added := 0
+	for _, route := range sorted {
+		if _, ok := routes[route]; ok || route == "" {
+			continue
+		}
+		if len(codes) >= math.MaxUint16 {
+			return added, ErrDictionaryFull
+		}
+
+		code := routeCode(route)
+		for {
+			if _, ok := codes[code]; !ok {
+				break
+			}
+			log.Println(fmt.Sprintf("route code collision(route: %s, code: %d, bound route: %s)", route, code, codes[code]))
+			code = code%math.MaxUint16 + 1
+		}
+		routes[route] = code
+		codes[code] = route
+		added++
+	}
+	return added, nil
+}
+
+// routeCode returns the code derived from the route in range [1, 65535]
+func routeCode(route string) uint16 {
+	h := fnv.New32a()
+	h.Write([]byte(route))
+	return uint16(h.Sum32()%math.MaxUint16) + 1
+}
+
+// ResetDictionary removes all routes from the dictionary
+func ResetDictionary() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	routes = make(map[string]uint16)
+	codes = make(map[uint16]string)
+}
+
+// Dictionary returns a copy of the routes map which be used to compress route
+func Dictionary() map[string]uint16 {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	dict := make(map[string]uint16, len(routes))
+	for route, code := range routes {
+		dict[route] = code
+	}
+	return dict
+}
+
+// DictionaryHash returns the hash of routes map, the client can cache the
+// dictionary with the hash. It returns an empty string if no dictionary set.
+func DictionaryHash() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if len(routes) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(routes))
+	for route := range routes {
+		keys = append(keys, route)
+	}
+	sort.Strings(keys)
+
+	h := md5.New()
+	for _, route := range keys {
+		fmt.Fprintf(h, "%s:%d\n", route, routes[route])
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
@@ -1,6 +1,7 @@
 package message
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -162,3 +163,162 @@ func TestEncode(t *testing.T) {
 		t.Error("not equal")
 	}
 }
+
+func TestDictionary(t *testing.T) {
+	SetDictionary(map[string]uint16{"test.dict.a": 200})
+	hash := DictionaryHash()
+	if hash == "" {
+		t.Fatal("empty hash")
+	}
+	if code := Dictionary()["test.dict.a"]; code != 200 {
+		t.Fatalf("expect code 200, got %d", code)
+	}
+	if DictionaryHash() != hash {
+		t.Fatal("hash is not stable")
+	}
+
+	SetDictionary(map[string]uint16{"test.dict.b": 201})
+	if DictionaryHash() == hash {
+		t.Fatal("hash is not changed after dictionary updated")
+	}
+}
+
+func TestSetDictionaryConflict(t *testing.T) {
+	if err := SetDictionary(map[string]uint16{"test.conflict.a": 300}); err != nil {
+		t.Fatal(err)
+	}
+	// the same binding can be set again
+	if err := SetDictionary(map[string]uint16{"test.conflict.a": 300}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDictionary(map[string]uint16{"test.conflict.a": 301}); err == nil {
+		t.Fatal("expect route conflict error")
+	}
+	if err := SetDictionary(map[string]uint16{"test.conflict.b": 300}); err == nil {
+		t.Fatal("expect code conflict error")
+	}
+	if err := SetDictionary(map[string]uint16{"test.conflict.c": 302, "test.conflict.d": 302}); err == nil {
+		t.Fatal("expect code conflict error")
+	}
+	if _, ok := Dictionary()["test.conflict.c"]; ok {
+		t.Fatal("the rejected dictionary should not be set")
+	}
+}
+
+func TestAddRoutes(t *testing.T) {
+	code := routeCode("test.auto.a")
+	if code != routeCode("test.auto.a") || code == 0 {
+		t.Fatalf("unstable route code %d", code)
+	}
+
+	// the route bound by SetDictionary keeps its code
+	if err := SetDictionary(map[string]uint16{"test.auto.manual": 400}); err != nil {
+		t.Fatal(err)
+	}
+	// take the code of test.auto.b to make a collision
+	if err := SetDictionary(map[string]uint16{"test.auto.taken": routeCode("test.auto.b")}); err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := AddRoutes([]string{"test.auto.b", "test.auto.a", "test.auto.manual", "test.auto.a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 2 {
+		t.Fatalf("expect 2 routes added, got %d", added)
+	}
+
+	dict := Dictionary()
+	if dict["test.auto.a"] != code {
+		t.Fatalf("expect code %d, got %d", code, dict["test.auto.a"])
+	}
+	if dict["test.auto.manual"] != 400 {
+		t.Fatalf("expect code 400, got %d", dict["test.auto.manual"])
+	}
+	if dict["test.auto.b"] == dict["test.auto.taken"] {
+		t.Fatal("collided code is not resolved")
+	}
+
+	m := &Message{Type: Notify, Route: "test.auto.b", Data: []byte("hello")}
+	data, err := Encode(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dm.Route != "test.auto.b" || !dm.compressed {
+		t.Fatalf("unexpected decoded message %v", dm)
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	m := &Message{
+		Type:  Response,
+		ID:    100,
+		Data:  []byte(`{"code":500}`),
+		Error: true,
+	}
+	em, err := m.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dm, err := Decode(em)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, dm) {
+		t.Fatalf("not equal, expect %+v, got %+v", m, dm)
+	}
+}
+
+func TestAddRoutes_Collision(t *testing.T) {
+	// find two routes whose codes collide
+	seen := map[uint16]string{}
+	var a, b string
+	for i := 0; a == ""; i++ {
+		route := fmt.Sprintf("test.order.%d", i)
+		if r, ok := seen[routeCode(route)]; ok {
+			a, b = r, route
+		}
+		seen[routeCode(route)] = route
+	}
+
+	dict := Dictionary()
+	defer func() {
+		ResetDictionary()
+		SetDictionary(dict)
+	}()
+
+	// The code published for the first route is kept when a colliding route added
+	for _, first := range []string{a, b} {
+		ResetDictionary()
+		AddRoutes([]string{first})
+		code := Dictionary()[first]
+
+		second := a
+		if first == a {
+			second = b
+		}
+		AddRoutes([]string{second})
+		current := Dictionary()
+		if current[first] != code {
+			t.Fatalf("the code of %s changed from %d to %d", first, code, current[first])
+		}
+		if current[second] == code {
+			t.Fatalf("collided code is not resolved: %v", current)
+		}
+	}
+
+	// The routes added together are assigned in the same way regardless of order
+	ResetDictionary()
+	AddRoutes([]string{a, b})
+	ordered := Dictionary()
+
+	ResetDictionary()
+	AddRoutes([]string{b, a})
+	if reversed := Dictionary(); !reflect.DeepEqual(ordered, reversed) {
+		t.Fatalf("expect %v, got %v", ordered, reversed)
+	}
+}
@@ -46,6 +46,10 @@ const (
 	Kick = 0x05 // disconnect message from server
 )
 
+// ProtocolVersion represents the version of packet and message protocol, which
+// is announced to clients in handshake response
+const ProtocolVersion = "1.0"
+
 // ErrWrongPacketType represents a wrong packet type.
 var ErrWrongPacketType = errors.New("wrong packet type")
 
@@ -1,6 +1,7 @@
 package nano
 
 import (
+	"crypto/tls"
 	"net/http"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/revzim/nano/internal/message"
 	"github.com/revzim/nano/pipeline"
 	"github.com/revzim/nano/serialize"
+	"github.com/revzim/nano/session"
 	"google.golang.org/grpc"
 )
 
@@ -69,6 +71,157 @@ func WithAdvertiseAddr(addr string, retryInterval ...time.Duration) Option {
 	}
 }
 
+// WithStandby sets the option to indicate whether the current node is a standby master,
+// which registers to the master as a member and takes over the master role when
+// the master is unreachable
+func WithStandby() Option {
+	return func(opt *cluster.Options) {
+		opt.IsStandby = true
+	}
+}
+
+// WithStandbyAddrs sets the addresses of standby masters, the current node will fail
+// over to them in order when the master is unreachable
+func WithStandbyAddrs(addrs ...string) Option {
+	return func(opt *cluster.Options) {
+		opt.StandbyAddrs = addrs
+	}
+}
+
+// WithDiscovery sets the discovery which maintains the cluster membership instead
+// of the master node, e.g: cluster.NewMemoryDiscovery, cluster.NewFileDiscovery
+func WithDiscovery(discovery cluster.Discovery) Option {
+	return func(opt *cluster.Options) {
+		opt.Discovery = discovery
+	}
+}
+
+// WithRouteSelector sets the RouteSelector of the service, which selects the member
+// to handle the messages of the service in cluster, e.g: cluster.NewConsistentHashSelector
+func WithRouteSelector(service string, selector cluster.RouteSelector) Option {
+	return func(opt *cluster.Options) {
+		if opt.RouteSelectors == nil {
+			opt.RouteSelectors = map[string]cluster.RouteSelector{}
+		}
+		opt.RouteSelectors[service] = selector
+	}
+}
+
+// WithStreamTransport sets the messages between cluster members are transferred by
+// long-lived bidirectional gRPC streams, the messages of all sessions between two
+// members are multiplexed over one stream, which is more efficient than unary calls
+func WithStreamTransport() Option {
+	return func(opt *cluster.Options) {
+		opt.StreamTransport = true
+	}
+}
+
+// WithDrainTimeout sets the max duration of draining the current node before shutdown,
+// the draining is triggered by signal or nano.Drain, and it is disabled by default
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(opt *cluster.Options) {
+		opt.DrainTimeout = timeout
+	}
+}
+
+// WithSessionSync sets the keys of session data which will be synchronized from
+// the gate session to the backend sessions, the uid is always synchronized. The
+// backends can write the values back to the gate by session.Session.Sync.
+func WithSessionSync(keys ...string) Option {
+	return func(opt *cluster.Options) {
+		opt.SyncKeys = keys
+	}
+}
+
+// WithSessionStore sets the Store which persists the session keys opted in by
+// session.Session.Persist, the state is kept in memory by default
+func WithSessionStore(store session.Store) Option {
+	return func(opt *cluster.Options) {
+		opt.SessionStore = store
+	}
+}
+
+// WithDuplicateLogin sets the policy applied when a uid is bound to a session while
+// another session has been bound to the same uid, multiple sessions are allowed by
+// default, see cluster.DuplicateLoginPolicy
+func WithDuplicateLogin(policy cluster.DuplicateLoginPolicy) Option {
+	return func(opt *cluster.Options) {
+		opt.DuplicateLogin = policy
+	}
+}
+
+// WithHandshakeTimeout sets the deadline of clients finishing handshake after connected,
+// it is disabled by default
+func WithHandshakeTimeout(timeout time.Duration) Option {
+	return func(opt *cluster.Options) {
+		opt.HandshakeTimeout = timeout
+	}
+}
+
+// WithMaxSessions sets the max count of concurrent sessions of gate, the connection
+// exceeding the cap will be kicked with cluster.AdmissionKick
+func WithMaxSessions(max int) Option {
+	return func(opt *cluster.Options) {
+		opt.MaxSessions = max
+	}
+}
+
+// WithMaxSessionsPerIP sets the max count of concurrent sessions from the same remote IP,
+// the connection exceeding the cap will be kicked with cluster.AdmissionKick
+func WithMaxSessionsPerIP(max int) Option {
+	return func(opt *cluster.Options) {
+		opt.MaxSessionsPerIP = max
+	}
+}
+
+// WithInterceptors adds the global interceptors of all handlers, see
+// component.Interceptor
+func WithInterceptors(interceptors ...component.Interceptor) Option {
+	return func(opt *cluster.Options) {
+		opt.Interceptors = append(opt.Interceptors, interceptors...)
+	}
+}
+
+// WithRequestTimeout sets the deadline of the messages from clients, which is
+// propagated to the handlers in cluster by the context.Context argument
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(opt *cluster.Options) {
+		opt.RequestTimeout = timeout
+	}
+}
+
+// WithRateLimit limits the messages sent by clients with token buckets per session,
+// per remote IP and per route, the messages exceeding the limits will be dropped,
+// delayed or the session will be kicked according to the action
+func WithRateLimit(opts *cluster.RateLimitOptions) Option {
+	return func(opt *cluster.Options) {
+		opt.RateLimit = opts
+	}
+}
+
+// WithSessionResume enables the clients resuming their sessions after reconnect, the
+// handshake response carries a resume token in `sys.resume`, and the client presents
+// it in the handshake request({"sys": {"resume": "token"}}) of the new connection.
+// The session whose connection was broken will be kept for the grace period, and
+// the members see the same session id after the session resumed.
+func WithSessionResume(grace time.Duration) Option {
+	return func(opt *cluster.Options) {
+		opt.SessionResume = grace
+	}
+}
+
+// WithMemberHeartbeat sets the interval of members renewing their lease in master,
+// a member will be evicted from cluster if it missed maxMissed(default 3) heartbeats
+// The heartbeat is disabled if the interval is not positive
+func WithMemberHeartbeat(interval time.Duration, maxMissed ...int) Option {
+	return func(opt *cluster.Options) {
+		opt.MemberHeartbeat = interval
+		if len(maxMissed) > 0 {
+			opt.MaxMissedHeartbeats = maxMissed[0]
+		}
+	}
+}
+
 // WithMemberAddr sets the listen address which is used to establish connection between
 // cluster members. Will select an available port automatically if no member address
 // setting and panic if no available port
@@ -92,6 +245,34 @@ func WithGrpcOptions(opts ...grpc.DialOption) Option {
 	}
 }
 
+// WithClusterTLS sets the certificate, key and CA certificate files which are used
+// to secure the gRPC traffic between cluster members with mutual TLS
+func WithClusterTLS(certFile, keyFile, caFile string) Option {
+	return func(opt *cluster.Options) {
+		config, err := cluster.NewClusterTLSConfig(certFile, keyFile, caFile)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		opt.ClusterTLS = config
+	}
+}
+
+// WithClusterTLSConfig sets the TLS config which is used to secure the gRPC traffic
+// between cluster members
+func WithClusterTLSConfig(config *tls.Config) Option {
+	return func(opt *cluster.Options) {
+		opt.ClusterTLS = config
+	}
+}
+
+// WithClusterToken sets the pre-shared cluster token, the gRPC calls from members
+// which do not carry the same token will be rejected
+func WithClusterToken(token string) Option {
+	return func(opt *cluster.Options) {
+		opt.ClusterToken = token
+	}
+}
+
 // WithComponents sets the Components
 func WithComponents(components *component.Components) Option {
 	return func(opt *cluster.Options) {
@@ -120,10 +301,28 @@ func WithDebugMode() Option {
 	}
 }
 
-// SetDictionary sets routes map
+// SetDictionary sets routes map, it panics if a route or code is duplicated
 func WithDictionary(dict map[string]uint16) Option {
 	return func(_ *cluster.Options) {
-		message.SetDictionary(dict)
+		if err := message.SetDictionary(dict); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// WithAutoDictionary generates the route dictionary from the handlers in cluster,
+// the routes set by WithDictionary keep their codes. See ExportDictionary.
+func WithAutoDictionary() Option {
+	return func(opt *cluster.Options) {
+		opt.AutoDictionary = true
+	}
+}
+
+// WithHandshakeUserData sets the custom data sent to client in the user section
+// of handshake response, the data will be encoded by JSON
+func WithHandshakeUserData(data interface{}) Option {
+	return func(_ *cluster.Options) {
+		env.HandshakeUserData = data
 	}
 }
 
@@ -32,6 +32,11 @@ func NewSerializer() *Serializer {
 	return &Serializer{}
 }
 
+// Name implements the serialize.Namer interface
+func (s *Serializer) Name() string {
+	return "json"
+}
+
 // Marshal returns the JSON encoding of v.
 func (s *Serializer) Marshal(v interface{}) ([]byte, error) {
 	return json.Marshal(v)
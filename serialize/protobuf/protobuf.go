@@ -37,6 +37,11 @@ func NewSerializer() *Serializer {
 	return &Serializer{}
 }
 
+// Name implements the serialize.Namer interface
+func (s *Serializer) Name() string {
+	return "protobuf"
+}
+
 // Marshal returns the protobuf encoding of v.
 func (s *Serializer) Marshal(v interface{}) ([]byte, error) {
 	pb, ok := v.(proto.Message)
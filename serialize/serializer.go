@@ -37,4 +37,19 @@ type (
 		Marshaler
 		Unmarshaler
 	}
+
+	// Namer is an optional interface implemented by Serializer, the name is
+	// announced to clients in handshake response
+	Namer interface {
+		Name() string
+	}
 )
+
+// Name returns the name of serializer, it returns an empty string if the
+// serializer does not implement Namer
+func Name(s Serializer) string {
+	if n, ok := s.(Namer); ok {
+		return n.Name()
+	}
+	return ""
+}
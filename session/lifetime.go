@@ -1,31 +1,240 @@
 package session
 
+import "sync"
+
+// CloseReason represents why a session was closed
+type CloseReason int32
+
+const (
+	// CloseReasonUnknown indicates the reason is unknown
+	CloseReasonUnknown CloseReason = iota
+	// CloseReasonClient indicates the client closed the connection
+	CloseReasonClient
+	// CloseReasonServer indicates the session was closed by Session.Close
+	CloseReasonServer
+	// CloseReasonKick indicates the session was kicked by Session.Kick
+	CloseReasonKick
+	// CloseReasonHeartbeatTimeout indicates the client missed heartbeats
+	CloseReasonHeartbeatTimeout
+	// CloseReasonReadError indicates reading from the connection failed
+	CloseReasonReadError
+	// CloseReasonWriteError indicates writing to the connection failed
+	CloseReasonWriteError
+	// CloseReasonDecodeError indicates the client sent malformed packets or
+	// violated the protocol, e.g: sent data before handshake
+	CloseReasonDecodeError
+	// CloseReasonShutdown indicates the application is shutting down
+	CloseReasonShutdown
+	// CloseReasonResumed indicates the session was discarded because the
+	// client resumed its previous session on the connection
+	CloseReasonResumed
+	// CloseReasonHandshakeTimeout indicates the client did not finish
+	// handshake in time
+	CloseReasonHandshakeTimeout
+)
+
+var closeReasonNames = map[CloseReason]string{
+	CloseReasonUnknown:          "unknown",
+	CloseReasonClient:           "client",
+	CloseReasonServer:           "server",
+	CloseReasonKick:             "kick",
+	CloseReasonHeartbeatTimeout: "heartbeat timeout",
+	CloseReasonReadError:        "read error",
+	CloseReasonWriteError:       "write error",
+	CloseReasonDecodeError:      "decode error",
+	CloseReasonShutdown:         "shutdown",
+	CloseReasonResumed:          "resumed",
+	CloseReasonHandshakeTimeout: "handshake timeout",
+}
+
+// String implements the fmt.Stringer interface
+func (r CloseReason) String() string {
+	if name, ok := closeReasonNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
 type (
 	// LifetimeHandler represents a callback
 	// that will be called when a session close or
 	// session low-level connection broken.
 	LifetimeHandler func(*Session)
 
+	// BindHandler represents a callback that will be called when
+	// a uid is bound to or unbound from a session.
+	BindHandler func(s *Session, uid int64)
+
+	// ReplacedHandler represents a callback that will be called when
+	// a session is replaced by a new session bound to the same uid.
+	ReplacedHandler func(old, new *Session)
+
 	lifetime struct {
+		sync.RWMutex
+		// callbacks that emitted on session created
+		onCreated []LifetimeHandler
+		// callbacks that emitted on session handshake
+		onHandshake []LifetimeHandler
+		// callbacks that emitted on uid bound
+		onBind []BindHandler
+		// callbacks that emitted on uid unbound
+		onUnbind []BindHandler
 		// callbacks that emitted on session closed
 		onClosed []LifetimeHandler
+		// callbacks that emitted on session replaced
+		onReplaced []ReplacedHandler
 	}
 )
 
+// Lifetime is the global listeners of all sessions, the listeners of a
+// specified session can be set by Session.Lifetime, which will be called
+// after the global ones.
 var Lifetime = &lifetime{}
 
+// OnCreated set the Callback which will be called when session is created,
+// e.g: a client connected to the gate, or a session routed to the member.
+func (lt *lifetime) OnCreated(h LifetimeHandler) {
+	lt.Lock()
+	defer lt.Unlock()
+
+	lt.onCreated = append(lt.onCreated, h)
+}
+
+// OnHandshake set the Callback which will be called when the client of
+// session finished handshake.
+func (lt *lifetime) OnHandshake(h LifetimeHandler) {
+	lt.Lock()
+	defer lt.Unlock()
+
+	lt.onHandshake = append(lt.onHandshake, h)
+}
+
+// OnBind set the Callback which will be called after a uid bound to session.
+func (lt *lifetime) OnBind(h BindHandler) {
+	lt.Lock()
+	defer lt.Unlock()
+
+	lt.onBind = append(lt.onBind, h)
+}
+
+// OnUnbind set the Callback which will be called after a uid unbound from
+// session.
+func (lt *lifetime) OnUnbind(h BindHandler) {
+	lt.Lock()
+	defer lt.Unlock()
+
+	lt.onUnbind = append(lt.onUnbind, h)
+}
+
 // OnClosed set the Callback which will be called
 // when session is closed Waring: session has closed.
+// The reason can be retrieved by Session.CloseReason.
 func (lt *lifetime) OnClosed(h LifetimeHandler) {
+	lt.Lock()
+	defer lt.Unlock()
+
 	lt.onClosed = append(lt.onClosed, h)
 }
 
-func (lt *lifetime) Close(s *Session) {
-	if len(lt.onClosed) < 1 {
-		return
+// OnReplaced set the Callback which will be called when the session is
+// replaced by a new login of the same uid, the state of old session can
+// be migrated to the new session in the callback. The old session will
+// be closed after that.
+func (lt *lifetime) OnReplaced(h ReplacedHandler) {
+	lt.Lock()
+	defer lt.Unlock()
+
+	lt.onReplaced = append(lt.onReplaced, h)
+}
+
+func (lt *lifetime) Create(s *Session) {
+	lt.RLock()
+	handlers := lt.onCreated
+	lt.RUnlock()
+
+	for _, h := range handlers {
+		h(s)
 	}
+	if l := s.listeners(lt); l != nil {
+		l.Create(s)
+	}
+}
+
+func (lt *lifetime) Handshake(s *Session) {
+	lt.RLock()
+	handlers := lt.onHandshake
+	lt.RUnlock()
+
+	for _, h := range handlers {
+		h(s)
+	}
+	if l := s.listeners(lt); l != nil {
+		l.Handshake(s)
+	}
+}
+
+func (lt *lifetime) Bind(s *Session, uid int64) {
+	lt.RLock()
+	handlers := lt.onBind
+	lt.RUnlock()
+
+	for _, h := range handlers {
+		h(s, uid)
+	}
+	if l := s.listeners(lt); l != nil {
+		l.Bind(s, uid)
+	}
+}
+
+func (lt *lifetime) Unbind(s *Session, uid int64) {
+	lt.RLock()
+	handlers := lt.onUnbind
+	lt.RUnlock()
+
+	for _, h := range handlers {
+		h(s, uid)
+	}
+	if l := s.listeners(lt); l != nil {
+		l.Unbind(s, uid)
+	}
+}
+
+// Close cancels the context of session and calls the closed callbacks, the
+// reason is unknown, see CloseWithReason
+func (lt *lifetime) Close(s *Session) {
+	lt.CloseWithReason(s, CloseReasonUnknown)
+}
+
+// CloseWithReason records the reason, cancels the context of session and calls
+// the closed callbacks, the reason recorded first will be kept
+func (lt *lifetime) CloseWithReason(s *Session, reason CloseReason) {
+	s.setCloseReason(reason)
+	s.cancel()
+
+	lt.RLock()
+	handlers := lt.onClosed
+	lt.RUnlock()
 
-	for _, h := range lt.onClosed {
+	for _, h := range handlers {
 		h(s)
 	}
+	if l := s.listeners(lt); l != nil {
+		l.CloseWithReason(s, reason)
+	}
+	if lt == Lifetime {
+		s.releaseState()
+	}
+}
+
+func (lt *lifetime) Replace(old, new *Session) {
+	lt.RLock()
+	handlers := lt.onReplaced
+	lt.RUnlock()
+
+	for _, h := range handlers {
+		h(old, new)
+	}
+	if l := old.listeners(lt); l != nil {
+		l.Replace(old, new)
+	}
 }
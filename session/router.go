@@ -44,3 +44,14 @@ func (r *Router) Find(service string) (string, bool) {
 	}
 	return v.(string), true
 }
+
+// UnbindAddress removes all remote services bound to the address, the services
+// will be bound to another address when they are used next time
+func (r *Router) UnbindAddress(address string) {
+	r.routes.Range(func(service, addr interface{}) bool {
+		if addr.(string) == address {
+			r.routes.Delete(service)
+		}
+		return true
+	})
+}
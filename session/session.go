@@ -21,6 +21,7 @@
 package session
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/revzim/nano/internal/log"
 	"github.com/revzim/nano/service"
 )
 
@@ -44,6 +46,36 @@ type NetworkEntity interface {
 	RemoteAddr() net.Addr
 }
 
+// Binder is an optional interface of NetworkEntity, which will be called before
+// a uid bound to the session, e.g: the cluster announces the binding to members
+type Binder interface {
+	Bind(uid int64) error
+}
+
+// Unbinder is an optional interface of NetworkEntity, which will be called before
+// the uid unbound from the session
+type Unbinder interface {
+	Unbind(uid int64) error
+}
+
+// Syncer is an optional interface of NetworkEntity, which writes the session data
+// back to the session of gate
+type Syncer interface {
+	Sync(data map[string]interface{}) error
+}
+
+// Kicker is an optional interface of NetworkEntity, which sends the reason to client
+// by a kick packet before closing the low-level connection
+type Kicker interface {
+	Kick(reason interface{}) error
+}
+
+// ContextRPC is an optional interface of NetworkEntity, which propagates the deadline
+// and metadata of the context to the remote handler
+type ContextRPC interface {
+	RPCContext(ctx context.Context, route string, v interface{}) error
+}
+
 type (
 	// Session represents a client session which could storage temp data during low-level
 	// keep connected, all data will be released when the low-level connection was broken.
@@ -58,6 +90,13 @@ type (
 		entity       NetworkEntity          // low-level network entity
 		data         map[string]interface{} // session data store
 		router       *Router
+		lifetime     *lifetime           // listeners of current session
+		closeReason  int32               // why the session was closed
+		store        Store               // store of persisted keys
+		storeMu      sync.Mutex          // serializes the writes to store
+		persisted    map[string]struct{} // keys written through to store
+		ctx          context.Context     // cancelled when the session closed
+		cancel       context.CancelFunc
 	}
 )
 
@@ -79,20 +118,37 @@ var (
 // New returns a new session instance
 // a NetworkEntity is a low-level network instance
 func New(entity NetworkEntity) *Session {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Session{
 		id:       service.Connections.SessionID(),
 		entity:   entity,
 		data:     make(map[string]interface{}),
 		lastTime: time.Now().Unix(),
 		router:   newRouter(),
+		ctx:      ctx,
+		cancel:   cancel,
 	}
 }
 
 // NetworkEntity returns the low-level network agent object
 func (s *Session) NetworkEntity() NetworkEntity {
+	s.RLock()
+	defer s.RUnlock()
+
 	return s.entity
 }
 
+// Reattach attaches the session to a new low-level network entity, e.g: the client
+// reconnected and resumed the session. The session id, uid, state and router
+// bindings are kept
+func (s *Session) Reattach(entity NetworkEntity) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.entity = entity
+	s.lastTime = time.Now().Unix()
+}
+
 // NetworkEntity returns the service router
 func (s *Session) Router() *Router {
 	return s.router
@@ -100,23 +156,39 @@ func (s *Session) Router() *Router {
 
 // RPC sends message to remote server
 func (s *Session) RPC(route string, v interface{}) error {
-	return s.entity.RPC(route, v)
+	return s.NetworkEntity().RPC(route, v)
+}
+
+// RPCContext sends message to remote server, the deadline and metadata of ctx
+// are propagated to the remote handler
+func (s *Session) RPCContext(ctx context.Context, route string, v interface{}) error {
+	entity := s.NetworkEntity()
+	if rpc, ok := entity.(ContextRPC); ok {
+		return rpc.RPCContext(ctx, route, v)
+	}
+	return entity.RPC(route, v)
+}
+
+// Context returns the context of session, which will be cancelled when the
+// session closed, the handlers can stop the work of the session with it
+func (s *Session) Context() context.Context {
+	return s.ctx
 }
 
 // Push message to client
 func (s *Session) Push(route string, v interface{}) error {
-	return s.entity.Push(route, v)
+	return s.NetworkEntity().Push(route, v)
 }
 
 // Response message to client
 func (s *Session) Response(v interface{}) error {
-	return s.entity.Response(v)
+	return s.NetworkEntity().Response(v)
 }
 
 // ResponseMID responses message to client, mid is
 // request message ID
 func (s *Session) ResponseMID(mid uint64, v interface{}) error {
-	return s.entity.ResponseMid(mid, v)
+	return s.NetworkEntity().ResponseMid(mid, v)
 }
 
 // ID returns the session id
@@ -131,7 +203,7 @@ func (s *Session) UID() int64 {
 
 // LastMid returns the last message id
 func (s *Session) LastMid() uint64 {
-	return s.entity.LastMid()
+	return s.NetworkEntity().LastMid()
 }
 
 // Bind bind UID to current session
@@ -140,12 +212,74 @@ func (s *Session) Bind(uid int64) error {
 		return ErrIllegalUID
 	}
 
+	if binder, ok := s.NetworkEntity().(Binder); ok {
+		if err := binder.Bind(uid); err != nil {
+			return err
+		}
+	}
+
+	prev := s.StoreKey()
 	atomic.StoreInt64(&s.uid, uid)
 	// s.uuid = uuid.New().String()
 	s.initUUID("")
+	s.bindState(prev)
+	Lifetime.Bind(s, uid)
 	return nil
 }
 
+// Unbind unbinds the uid from current session
+func (s *Session) Unbind() error {
+	uid := s.UID()
+	if uid < 1 {
+		return nil
+	}
+
+	if unbinder, ok := s.NetworkEntity().(Unbinder); ok {
+		if err := unbinder.Unbind(uid); err != nil {
+			return err
+		}
+	}
+
+	atomic.StoreInt64(&s.uid, 0)
+	Lifetime.Unbind(s, uid)
+	return nil
+}
+
+// Lifetime returns the listeners of current session, which will be called
+// after the global listeners in session.Lifetime
+func (s *Session) Lifetime() *lifetime {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.lifetime == nil {
+		s.lifetime = &lifetime{}
+	}
+	return s.lifetime
+}
+
+// listeners returns the listeners of current session if the caller is the
+// global listeners
+func (s *Session) listeners(caller *lifetime) *lifetime {
+	if caller != Lifetime {
+		return nil
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.lifetime
+}
+
+// CloseReason returns why the session was closed, it is CloseReasonUnknown
+// before the session closed
+func (s *Session) CloseReason() CloseReason {
+	return CloseReason(atomic.LoadInt32(&s.closeReason))
+}
+
+func (s *Session) setCloseReason(reason CloseReason) {
+	atomic.CompareAndSwapInt32(&s.closeReason, int32(CloseReasonUnknown), int32(reason))
+}
+
 func (s *Session) initUUID(newUUID string) {
 	if s.uuid == "" {
 		if newUUID == "" {
@@ -168,28 +302,181 @@ func (s *Session) ShortUUID() string {
 // Close terminate current session, session related data will not be released,
 // all related data should be Clear explicitly in Session closed callback
 func (s *Session) Close() {
-	s.entity.Close()
+	s.NetworkEntity().Close()
+}
+
+// Kick sends the reason to client by a kick packet after the pending messages, and
+// then closes the low-level connection, so that the client can tell why it was
+// disconnected. The session will be closed directly if the network entity does
+// not support kicking
+func (s *Session) Kick(reason interface{}) error {
+	entity := s.NetworkEntity()
+	if kicker, ok := entity.(Kicker); ok {
+		return kicker.Kick(reason)
+	}
+	return entity.Close()
 }
 
 // RemoteAddr returns the remote network address.
 func (s *Session) RemoteAddr() net.Addr {
-	return s.entity.RemoteAddr()
+	return s.NetworkEntity().RemoteAddr()
 }
 
 // Remove delete data associated with the key from session storage
 func (s *Session) Remove(key string) {
 	s.Lock()
-	defer s.Unlock()
-
 	delete(s.data, key)
+	_, persisted := s.persisted[key]
+	s.Unlock()
+
+	if persisted {
+		s.persist()
+	}
 }
 
 // Set associates value with the key in session storage
 func (s *Session) Set(key string, value interface{}) {
+	s.Lock()
+	s.data[key] = value
+	_, persisted := s.persisted[key]
+	s.Unlock()
+
+	if persisted {
+		s.persist()
+	}
+}
+
+// StoreKey returns the key of session state in Store, which is the UIDStoreKey
+// if a uid bound, otherwise the SessionStoreKey
+func (s *Session) StoreKey() string {
+	if uid := s.UID(); uid > 0 {
+		return UIDStoreKey(uid)
+	}
+	return SessionStoreKey(s.id)
+}
+
+// SetStore sets the Store of the keys opted in by Persist, DefaultStore is used
+// if no Store set. It should be called before Persist, e.g: the cluster node sets
+// the Store of node options when the session created.
+func (s *Session) SetStore(store Store) {
 	s.Lock()
 	defer s.Unlock()
 
-	s.data[key] = value
+	s.store = store
+}
+
+// Persist restores the keys from the Store, and writes them through to the store
+// whenever they are set or removed, the write failures of Set and Remove are
+// logged. The keys which have values in session will not be overwritten. The state saved with SessionStoreKey is moved
+// to UIDStoreKey when a uid bound, and the state of the uid is restored. The
+// state saved with SessionStoreKey is deleted when the session closed.
+func (s *Session) Persist(keys ...string) error {
+	s.Lock()
+	if s.store == nil {
+		s.store = DefaultStore
+	}
+	store := s.store
+	s.Unlock()
+
+	data, err := store.Load(s.StoreKey())
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	if s.persisted == nil {
+		s.persisted = map[string]struct{}{}
+	}
+	for _, key := range keys {
+		s.persisted[key] = struct{}{}
+		if _, found := s.data[key]; found {
+			continue
+		}
+		if value, found := data[key]; found {
+			s.data[key] = value
+		}
+	}
+	s.Unlock()
+	return s.save()
+}
+
+// bindState moves the persisted state saved with the previous key to the key of
+// bound uid, the state of the uid is restored for the keys which have no values
+func (s *Session) bindState(prev string) {
+	s.RLock()
+	store, persisted := s.store, len(s.persisted) > 0
+	s.RUnlock()
+	if !persisted || prev == s.StoreKey() {
+		return
+	}
+
+	data, err := store.Load(s.StoreKey())
+	if err != nil {
+		log.Println(fmt.Sprintf("Restore session state failed, SessionID=%d, UID=%d, Error=%s", s.id, s.UID(), err.Error()))
+	}
+	s.Lock()
+	for key := range s.persisted {
+		if _, found := s.data[key]; found {
+			continue
+		}
+		if value, found := data[key]; found {
+			s.data[key] = value
+		}
+	}
+	s.Unlock()
+
+	s.persist()
+	// The state of the uid bound before is kept
+	if prev == SessionStoreKey(s.id) {
+		s.deleteState(store, prev)
+	}
+}
+
+// releaseState deletes the persisted state saved with the SessionStoreKey when
+// the session closed, the state of uid is kept
+func (s *Session) releaseState() {
+	s.RLock()
+	store, persisted := s.store, len(s.persisted) > 0
+	s.RUnlock()
+	if !persisted || s.UID() > 0 {
+		return
+	}
+	s.deleteState(store, SessionStoreKey(s.id))
+}
+
+// save writes the persisted keys to store, the writes of session are serialized
+// so that the latest state is saved at last
+func (s *Session) save() error {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+
+	s.RLock()
+	store := s.store
+	data := make(map[string]interface{}, len(s.persisted))
+	for key := range s.persisted {
+		if value, found := s.data[key]; found {
+			data[key] = value
+		}
+	}
+	s.RUnlock()
+
+	return store.Save(s.StoreKey(), data)
+}
+
+// persist saves the persisted keys after they changed, the failure is logged
+func (s *Session) persist() {
+	if err := s.save(); err != nil {
+		log.Println(fmt.Sprintf("Persist session state failed, SessionID=%d, UID=%d, Error=%s", s.id, s.UID(), err.Error()))
+	}
+}
+
+func (s *Session) deleteState(store Store, key string) {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+
+	if err := store.Delete(key); err != nil {
+		log.Println(fmt.Sprintf("Delete session state failed, SessionID=%d, Key=%s, Error=%s", s.id, key, err.Error()))
+	}
 }
 
 // HasKey decides whether a key has associated value
@@ -438,6 +725,45 @@ func (s *Session) State() map[string]interface{} {
 	return s.data
 }
 
+// Synchronize updates the uid and data synchronized from other members of cluster,
+// the Binder, Unbinder and lifetime callbacks will not be called
+func (s *Session) Synchronize(uid int64, data map[string]interface{}) {
+	atomic.StoreInt64(&s.uid, uid)
+	if uid > 0 {
+		s.initUUID("")
+	}
+
+	if len(data) < 1 {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	for key, value := range data {
+		s.data[key] = value
+	}
+}
+
+// Sync writes the values of keys back to the session of gate, so that they will
+// be synchronized to other members with the following messages. It does nothing
+// if the session is not a backend session of cluster.
+func (s *Session) Sync(keys ...string) error {
+	syncer, ok := s.NetworkEntity().(Syncer)
+	if !ok {
+		return nil
+	}
+
+	data := make(map[string]interface{}, len(keys))
+	s.RLock()
+	for _, key := range keys {
+		if value, found := s.data[key]; found {
+			data[key] = value
+		}
+	}
+	s.RUnlock()
+	return syncer.Sync(data)
+}
+
 // Restore session state after reconnect
 func (s *Session) Restore(data map[string]interface{}) {
 	s.Lock()
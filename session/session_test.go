@@ -1,6 +1,11 @@
 package session
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
 
 func TestNewSession(t *testing.T) {
 	s := New(nil)
@@ -173,3 +178,71 @@ func TestSession_Restore(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSession_Lifetime(t *testing.T) {
+	s := New(nil)
+	var events []string
+	s.Lifetime().OnBind(func(s *Session, uid int64) {
+		events = append(events, fmt.Sprintf("bind %d", uid))
+	})
+	s.Lifetime().OnUnbind(func(s *Session, uid int64) {
+		events = append(events, fmt.Sprintf("unbind %d", uid))
+	})
+	s.Lifetime().OnClosed(func(s *Session) {
+		events = append(events, fmt.Sprintf("closed by %s", s.CloseReason()))
+	})
+
+	s.Bind(100)
+	s.Unbind()
+	if s.UID() != 0 {
+		t.Fatalf("uid should be unbound, got %d", s.UID())
+	}
+	Lifetime.CloseWithReason(s, CloseReasonKick)
+	Lifetime.CloseWithReason(s, CloseReasonClient)
+
+	expect := []string{"bind 100", "unbind 100", "closed by kick", "closed by kick"}
+	if !reflect.DeepEqual(events, expect) {
+		t.Fatalf("expect events %v, got %v", expect, events)
+	}
+
+	// The listeners of other sessions are not called
+	New(nil).Bind(100)
+	if len(events) != len(expect) {
+		t.Fatalf("unexpected events %v", events)
+	}
+}
+
+func TestSession_Context(t *testing.T) {
+	s := New(nil)
+	if err := s.Context().Err(); err != nil {
+		t.Fatalf("unexpected context error %v", err)
+	}
+
+	Lifetime.CloseWithReason(s, CloseReasonServer)
+	if err := s.Context().Err(); err != context.Canceled {
+		t.Fatalf("expect context canceled, got %v", err)
+	}
+
+	// The session closed without reason
+	s = New(nil)
+	Lifetime.Close(s)
+	if err := s.Context().Err(); err != context.Canceled {
+		t.Fatalf("expect context canceled, got %v", err)
+	}
+	if s.CloseReason() != CloseReasonUnknown {
+		t.Fatalf("expect %s, got %s", CloseReasonUnknown, s.CloseReason())
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	ctx := WithMetadata(context.Background(), map[string]string{"trace": "t1", "span": "s1"})
+	ctx = WithMetadata(ctx, map[string]string{"span": "s2"})
+
+	md := MetadataFromContext(ctx)
+	if md["trace"] != "t1" || md["span"] != "s2" {
+		t.Fatalf("unexpected metadata %v", md)
+	}
+	if MetadataFromContext(context.Background()) != nil {
+		t.Fatal("expect nil metadata")
+	}
+}
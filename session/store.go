@@ -0,0 +1,179 @@
+// Copyright (c) nano Authors. All Rights Reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package session
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the session state, so that the state survives the restart of
+// gate and can be read by admin tools. The state is keyed by UIDStoreKey or
+// SessionStoreKey. The state is written through to the store synchronously by
+// the goroutine changing the session, so the store should be fast or local.
+type Store interface {
+	// Load returns the state saved with the key, it returns nil if not found
+	Load(key string) (map[string]interface{}, error)
+	// Save replaces the state saved with the key
+	Save(key string, data map[string]interface{}) error
+	// Delete deletes the state saved with the key
+	Delete(key string) error
+}
+
+// DefaultStore is the Store used by Session.Persist if the session has no Store
+// set, see Session.SetStore
+var DefaultStore Store = NewMemoryStore()
+
+// UIDStoreKey returns the store key of the session state bound to the uid
+func UIDStoreKey(uid int64) string {
+	return fmt.Sprintf("uid-%d", uid)
+}
+
+// SessionStoreKey returns the store key of the session state which is not
+// bound to a uid
+func SessionStoreKey(id int64) string {
+	return fmt.Sprintf("session-%d", id)
+}
+
+// MemoryStore is a Store which keeps the state in process memory
+type MemoryStore struct {
+	sync.RWMutex
+	states map[string]map[string]interface{}
+}
+
+// NewMemoryStore returns a new MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: map[string]map[string]interface{}{}}
+}
+
+// Load implements the Store interface
+func (m *MemoryStore) Load(key string) (map[string]interface{}, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	return copyState(m.states[key]), nil
+}
+
+// Save implements the Store interface
+func (m *MemoryStore) Save(key string, data map[string]interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.states[key] = copyState(data)
+	return nil
+}
+
+// Delete implements the Store interface
+func (m *MemoryStore) Delete(key string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.states, key)
+	return nil
+}
+
+func copyState(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	state := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		state[key] = value
+	}
+	return state
+}
+
+// FileStore is a Store which saves the state of each key to a file in the
+// directory. The state is encoded by gob, the types of values except the
+// builtin types should be registered by gob.Register.
+type FileStore struct {
+	sync.Mutex
+	dir string
+}
+
+// NewFileStore returns a new FileStore, the directory will be created if it
+// does not exist
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (f *FileStore) path(key string) string {
+	return filepath.Join(f.dir, url.PathEscape(key)+".gob")
+}
+
+// Load implements the Store interface
+func (f *FileStore) Load(key string) (map[string]interface{}, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	buf, err := ioutil.ReadFile(f.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{}
+	if err := gob.NewDecoder(bytes.NewReader(buf)).Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Save implements the Store interface, the file is replaced atomically
+func (f *FileStore) Save(key string, data map[string]interface{}) error {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(data); err != nil {
+		return err
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	path := f.path(key)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Delete implements the Store interface
+func (f *FileStore) Delete(key string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	err := os.Remove(f.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
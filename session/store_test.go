@@ -0,0 +1,141 @@
+package session
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nano-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := UIDStoreKey(100)
+	if data, err := store.Load(key); err != nil || data != nil {
+		t.Fatalf("unexpected state of unknown key: %v, %v", data, err)
+	}
+
+	state := map[string]interface{}{"level": 10, "name": "nano"}
+	if err := store.Save(key, state); err != nil {
+		t.Fatal(err)
+	}
+	data, err := store.Load(key)
+	if err != nil || !reflect.DeepEqual(data, state) {
+		t.Fatalf("expect state %v, got %v, %v", state, data, err)
+	}
+
+	if err := store.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.Load(key); err != nil || data != nil {
+		t.Fatalf("unexpected state of deleted key: %v, %v", data, err)
+	}
+}
+
+func TestSession_Persist(t *testing.T) {
+	store := NewMemoryStore()
+
+	s := New(nil)
+	s.SetStore(store)
+	s.Bind(100)
+	if err := s.Persist("level"); err != nil {
+		t.Fatal(err)
+	}
+	s.Set("level", 10)
+	s.Set("scene", "lobby")
+
+	data, _ := store.Load(UIDStoreKey(100))
+	if expect := map[string]interface{}{"level": 10}; !reflect.DeepEqual(data, expect) {
+		t.Fatalf("expect state %v, got %v", expect, data)
+	}
+
+	// The state is restored by the new session of the same uid
+	s2 := New(nil)
+	s2.SetStore(store)
+	s2.Bind(100)
+	if err := s2.Persist("level"); err != nil {
+		t.Fatal(err)
+	}
+	if s2.Int("level") != 10 {
+		t.Fatalf("expect level restored, got %d", s2.Int("level"))
+	}
+
+	s2.Remove("level")
+	if data, _ := store.Load(UIDStoreKey(100)); len(data) != 0 {
+		t.Fatalf("unexpected state after key removed: %v", data)
+	}
+}
+
+func TestSession_PersistBeforeBind(t *testing.T) {
+	store := NewMemoryStore()
+	store.Save(UIDStoreKey(200), map[string]interface{}{"level": 20, "scene": "lobby"})
+
+	s := New(nil)
+	s.SetStore(store)
+	if err := s.Persist("scene", "guide"); err != nil {
+		t.Fatal(err)
+	}
+	s.Set("guide", true)
+	if data, _ := store.Load(SessionStoreKey(s.ID())); !reflect.DeepEqual(data, map[string]interface{}{"guide": true}) {
+		t.Fatalf("unexpected state before bound: %v", data)
+	}
+
+	// The state is moved to the uid and the state of uid is restored
+	s.Bind(200)
+	if data, _ := store.Load(SessionStoreKey(s.ID())); data != nil {
+		t.Fatalf("the state of session key should be deleted, got %v", data)
+	}
+	expect := map[string]interface{}{"guide": true, "scene": "lobby"}
+	if data, _ := store.Load(UIDStoreKey(200)); !reflect.DeepEqual(data, expect) {
+		t.Fatalf("expect state %v, got %v", expect, data)
+	}
+	if s.String("scene") != "lobby" {
+		t.Fatalf("expect scene restored, got %s", s.String("scene"))
+	}
+
+	// The state of uid is kept after the session closed
+	Lifetime.Close(s)
+	if data, _ := store.Load(UIDStoreKey(200)); !reflect.DeepEqual(data, expect) {
+		t.Fatalf("expect state %v, got %v", expect, data)
+	}
+}
+
+func TestSession_ReleaseState(t *testing.T) {
+	store := NewMemoryStore()
+	s := New(nil)
+	s.SetStore(store)
+	if err := s.Persist("guide"); err != nil {
+		t.Fatal(err)
+	}
+	s.Set("guide", true)
+
+	// The state of session key is deleted after the session closed
+	Lifetime.Close(s)
+	if data, _ := store.Load(SessionStoreKey(s.ID())); data != nil {
+		t.Fatalf("the state of session key should be deleted, got %v", data)
+	}
+}
+
+// failingStore fails to save the state
+type failingStore struct{ *MemoryStore }
+
+func (failingStore) Save(string, map[string]interface{}) error {
+	return errors.New("store unavailable")
+}
+
+func TestSession_PersistFailed(t *testing.T) {
+	s := New(nil)
+	s.SetStore(failingStore{NewMemoryStore()})
+	if err := s.Persist("level"); err == nil || err.Error() != "store unavailable" {
+		t.Fatalf("expect the error of store, got %v", err)
+	}
+}